@@ -0,0 +1,102 @@
+package main
+
+import (
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/repositiries"
+	"awesomeProject/internal/service"
+	"awesomeProject/migrations"
+	"awesomeProject/pkg/migrate"
+	"awesomeProject/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"log/slog"
+	"os"
+)
+
+func runMigrate(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	fs := newFlagSet("migrate")
+	dir := fs.String("dir", "", "read the migration files from `path` instead of the embedded ones")
+	steps := fs.Int("steps", 1, "number of migrations down reverts")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	direction := fs.Arg(0)
+	if direction != "up" && direction != "down" {
+		return fmt.Errorf("unknown direction %q, want up or down", direction)
+	}
+
+	pool, err := postgres.NewPostgres(cfg.Postgres)
+	if err != nil {
+		return fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	defer pool.Close()
+
+	var files iofs.FS = migrations.FS
+	if *dir != "" {
+		files = os.DirFS(*dir)
+	}
+	m, err := migrate.New(pool, files, log)
+	if err != nil {
+		return err
+	}
+
+	var n int
+	verb := "applied"
+	if direction == "up" {
+		n, err = m.Up(ctx)
+	} else {
+		verb = "reverted"
+		n, err = m.Down(ctx, *steps)
+	}
+	if err != nil {
+		return err
+	}
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %d migrations, now at version %d\n", verb, n, version)
+
+	return nil
+}
+
+func runCreateApiKey(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	fs := newFlagSet("create-api-key")
+	role := fs.String("role", user.RoleEditor, "role of the user if it has to be created")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	name := fs.Arg(0)
+
+	pool, err := postgres.NewPostgres(cfg.Postgres)
+	if err != nil {
+		return fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	defer pool.Close()
+
+	users := repositiries.NewUserRepository(pool)
+	auth := service.NewAuthService(users)
+
+	u, err := users.GetByName(ctx, name)
+	if errors.Is(err, user.ErrNotFound) {
+		u, err = auth.CreateUser(ctx, name, *role)
+		if err == nil {
+			log.Info("created user", slog.String("name", u.Name), slog.String("role", *role))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	key, err := auth.CreateApiKey(ctx, u.Id)
+	if err != nil {
+		return err
+	}
+	// the key cannot be shown again, only its hash is stored
+	fmt.Println(key)
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/repositiries"
+	"awesomeProject/internal/service"
+	"awesomeProject/pkg/postgres"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// app holds the repositories and services shared by the server and the
+// commands managing links.
+type app struct {
+	pool       *pgxpool.Pool
+	urlRepo    repositiries.UrlRepository
+	domainRepo repositiries.DomainRepository
+	userRepo   repositiries.UserRepository
+	clickRepo  repositiries.ClickRepository
+	urls       service.UrlService
+	stats      service.StatsService
+}
+
+func newApp(cfg *config.Config, log *slog.Logger) (*app, error) {
+	pool, err := postgres.NewPostgres(cfg.Postgres)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	repo := repositiries.NewUrlRepository(pool)
+	generator, err := service.NewAliasGenerator(
+		service.GeneratorConfig{
+			Strategy:  cfg.Alias.Generator.Strategy,
+			MinLength: cfg.Alias.Generator.MinLength,
+			Salt:      cfg.Alias.Generator.Salt,
+			Words:     cfg.Alias.Generator.Words,
+			BlockSize: cfg.Alias.Generator.BlockSize,
+			Lowercase: !cfg.Alias.CaseSensitive,
+		},
+		repositiries.NewSequenceRepository(pool, "url_alias_seq"),
+		repositiries.NewCounterRepository(pool),
+	)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to create alias generator: %w", err)
+	}
+	aliasPolicy := service.AliasPolicy{
+		MinLength:     cfg.Alias.MinLength,
+		MaxLength:     cfg.Alias.MaxLength,
+		Charset:       cfg.Alias.Charset,
+		Reserved:      cfg.Alias.Reserved,
+		CaseSensitive: cfg.Alias.CaseSensitive,
+	}
+	domainRepo := repositiries.NewDomainRepository(pool)
+	clickRepo := repositiries.NewClickRepository(pool)
+	serv := service.NewUrlService(repo, domainRepo, generator, log, cfg.HTTPServer.BaseUrl, aliasPolicy, cfg.Dedupe)
+
+	return &app{
+		pool:       pool,
+		urlRepo:    repo,
+		domainRepo: domainRepo,
+		userRepo:   repositiries.NewUserRepository(pool),
+		clickRepo:  clickRepo,
+		urls:       service.NewUrlAccessPolicy(serv),
+		stats:      service.NewStatsAccessPolicy(service.NewStatsService(repo, clickRepo, log, aliasPolicy)),
+	}, nil
+}
+
+func (a *app) close() {
+	a.pool.Close()
+}
+
+// actAs returns ctx acting as the user called name, or as an admin outside
+// any workspace when name is empty. Links created by that admin have no
+// owner.
+func (a *app) actAs(ctx context.Context, name string) (context.Context, error) {
+	if name == "" {
+		return user.WithUser(ctx, user.User{Name: "cli", Roles: []string{user.RoleAdmin}}), nil
+	}
+
+	u, err := a.userRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("user %q: %w", name, err)
+	}
+	return user.WithUser(ctx, u), nil
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/domain/click"
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/service"
+	"awesomeProject/internal/transfer"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const defaultStatsRange = 7 * 24 * time.Hour
+
+// filterFlags registers the filters of a link listing on fs and returns a
+// function building the filter once fs is parsed.
+func filterFlags(fs *flag.FlagSet) func() (url.ListFilter, error) {
+	var f url.ListFilter
+	var tags stringsFlag
+	var from, to string
+	fs.StringVar(&f.AliasPrefix, "alias-prefix", "", "only aliases starting with `prefix`")
+	fs.StringVar(&f.Destination, "destination", "", "only destinations containing `text`")
+	fs.StringVar(&f.State, "state", "", "only active or expired links")
+	fs.Var(&tags, "tag", "only links tagged `tag`; may be repeated")
+	fs.StringVar(&from, "created-from", "", "only links created at or after `time` (RFC 3339)")
+	fs.StringVar(&to, "created-to", "", "only links created before `time` (RFC 3339)")
+
+	return func() (url.ListFilter, error) {
+		f.Tags = tags
+		for _, bound := range []struct {
+			name, value string
+			dst         *time.Time
+		}{
+			{"created-from", from, &f.CreatedFrom},
+			{"created-to", to, &f.CreatedTo},
+		} {
+			if bound.value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, bound.value)
+			if err != nil {
+				return url.ListFilter{}, fmt.Errorf("invalid -%s: %w", bound.name, err)
+			}
+			*bound.dst = t
+		}
+		return f, nil
+	}
+}
+
+// openAs opens the app for a command acting as the user called as.
+func openAs(ctx context.Context, cfg *config.Config, log *slog.Logger, as string) (*app, context.Context, error) {
+	a, err := newApp(cfg, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, err = a.actAs(ctx, as)
+	if err != nil {
+		a.close()
+		return nil, nil, err
+	}
+	return a, ctx, nil
+}
+
+func asFlag(fs *flag.FlagSet) *string {
+	return fs.String("as", "", "act as the user called `name` instead of an admin")
+}
+
+func runCreate(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	fs := newFlagSet("create")
+	as := asFlag(fs)
+	alias := fs.String("alias", "", "custom alias, generated if empty")
+	domain := fs.String("domain", "", "registered `host` to serve the link on")
+	ttl := fs.Duration("ttl", 0, "expire the link after this long")
+	expiresAt := fs.String("expires-at", "", "expire the link at `time` (RFC 3339)")
+	var details url.Details
+	var tags stringsFlag
+	fs.StringVar(&details.Title, "title", "", "title of the link")
+	fs.StringVar(&details.Description, "description", "", "description of the link")
+	fs.Var(&tags, "tag", "tag the link with `tag`; may be repeated")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	details.Tags = tags
+
+	var expiry time.Time
+	switch {
+	case *ttl != 0 && *expiresAt != "":
+		return errors.New("-ttl and -expires-at are mutually exclusive")
+	case *ttl < 0:
+		return errors.New("-ttl must be positive")
+	case *ttl > 0:
+		expiry = time.Now().Add(*ttl)
+	case *expiresAt != "":
+		t, err := time.Parse(time.RFC3339, *expiresAt)
+		if err != nil {
+			return fmt.Errorf("invalid -expires-at: %w", err)
+		}
+		expiry = t
+	}
+
+	a, ctx, err := openAs(ctx, cfg, log, *as)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	u, err := a.urls.Save(ctx, *domain, fs.Arg(0), *alias, expiry, details)
+	if err != nil {
+		return err
+	}
+	fmt.Println(a.urls.ShortUrl(u))
+
+	return nil
+}
+
+func runList(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	fs := newFlagSet("list")
+	as := asFlag(fs)
+	filter := filterFlags(fs)
+	limit := fs.Int("limit", 0, "links per page, up to 500")
+	sort := fs.String("sort", "", "created or clicks, prefixed with - for descending order")
+	cursor := fs.String("cursor", "", "resume after the page that printed `cursor`")
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	f, err := filter()
+	if err != nil {
+		return err
+	}
+	q := url.ListQuery{ListFilter: f, Limit: *limit}
+	q.Sort, q.Desc = strings.CutPrefix(*sort, "-")
+
+	a, ctx, err := openAs(ctx, cfg, log, *as)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	page, err := a.urls.List(ctx, q, *cursor)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSHORT URL\tDESTINATION\tCLICKS\tCREATED\tEXPIRES")
+	for _, u := range page.Urls {
+		expires := "-"
+		if !u.ExpiresAt.IsZero() {
+			expires = u.ExpiresAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\n",
+			u.Id, a.urls.ShortUrl(u), u.OriginalUrl, u.Clicks, u.CreatedAt.Format(time.RFC3339), expires)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if page.NextCursor != "" {
+		fmt.Fprintf(os.Stderr, "more links follow, continue with -cursor %s\n", page.NextCursor)
+	}
+
+	return nil
+}
+
+func runDelete(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	fs := newFlagSet("delete")
+	as := asFlag(fs)
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+	ids := make([]int, fs.NArg())
+	for i, arg := range fs.Args() {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid id %q", arg)
+		}
+		ids[i] = id
+	}
+
+	a, ctx, err := openAs(ctx, cfg, log, *as)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	results, err := a.urls.DeleteMany(ctx, ids)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for i, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "%d: %v\n", ids[i], result.Err)
+		}
+	}
+	fmt.Printf("deleted %d of %d links\n", len(ids)-failed, len(ids))
+	if failed > 0 {
+		return fmt.Errorf("%d links were not deleted", failed)
+	}
+
+	return nil
+}
+
+func runImport(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	fs := newFlagSet("import")
+	as := asFlag(fs)
+	dryRun := fs.Bool("dry-run", false, "only validate the rows")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	var file io.Reader = os.Stdin
+	if name := fs.Arg(0); name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		file = f
+	}
+	rows, err := transfer.ReadCSV(file, service.MaxBulkItems)
+	if err != nil {
+		return err
+	}
+
+	var drafts []url.Draft
+	var draftIdx []int
+	rowErrs := make([]error, len(rows))
+	for i, row := range rows {
+		rowErrs[i] = row.Err
+		if row.Err == nil {
+			drafts = append(drafts, row.Draft)
+			draftIdx = append(draftIdx, i)
+		}
+	}
+
+	a, ctx, err := openAs(ctx, cfg, log, *as)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	var results []url.ItemResult
+	if *dryRun {
+		results, err = a.urls.CheckMany(ctx, drafts)
+	} else {
+		results, err = a.urls.SaveMany(ctx, drafts)
+	}
+	if err != nil {
+		return err
+	}
+	for j, result := range results {
+		rowErrs[draftIdx[j]] = result.Err
+	}
+
+	failed := 0
+	for i, err := range rowErrs {
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "line %d: %v\n", rows[i].Line, err)
+		}
+	}
+	verb := "imported"
+	if *dryRun {
+		verb = "valid"
+	}
+	fmt.Printf("%s %d of %d rows\n", verb, len(rows)-failed, len(rows))
+	if failed > 0 {
+		return fmt.Errorf("%d rows failed", failed)
+	}
+
+	return nil
+}
+
+func runExport(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	fs := newFlagSet("export")
+	as := asFlag(fs)
+	filter := filterFlags(fs)
+	format := fs.String("format", transfer.FormatCSV, "csv, json or ndjson")
+	out := fs.String("o", "-", "write to `file` instead of stdout")
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	f, err := filter()
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	enc, err := transfer.NewEncoder(*format, w)
+	if err != nil {
+		return fmt.Errorf("%w %q", err, *format)
+	}
+
+	a, ctx, err := openAs(ctx, cfg, log, *as)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	if err := enc.Begin(); err != nil {
+		return err
+	}
+	err = a.urls.Export(ctx, f, func(u url.Url) error {
+		return enc.Encode(transfer.NewRecord(u, a.urls.ShortUrl(u)))
+	})
+	if err != nil {
+		return err
+	}
+
+	return enc.End()
+}
+
+func runStats(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	fs := newFlagSet("stats")
+	as := asFlag(fs)
+	domain := fs.String("domain", "", "registered `host` the link is served on")
+	interval := fs.String("interval", click.IntervalDay, "hour or day")
+	from := fs.String("from", "", "start of the range (RFC 3339), seven days before -to by default")
+	to := fs.String("to", "", "end of the range (RFC 3339), now by default")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	end := time.Now()
+	if *to != "" {
+		t, err := time.Parse(time.RFC3339, *to)
+		if err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+		end = t
+	}
+	start := end.Add(-defaultStatsRange)
+	if *from != "" {
+		t, err := time.Parse(time.RFC3339, *from)
+		if err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+		start = t
+	}
+
+	a, ctx, err := openAs(ctx, cfg, log, *as)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	stats, err := a.stats.Stats(ctx, *domain, fs.Arg(0), start, end, *interval)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("clicks %d, unique visitors %d from %s to %s\n",
+		stats.TotalClicks, stats.UniqueVisitors, stats.From.Format(time.RFC3339), stats.To.Format(time.RFC3339))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "START\tCLICKS\tUNIQUE")
+	for _, b := range stats.Series {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", b.Start.Format(time.RFC3339), b.Clicks, b.UniqueVisitors)
+	}
+
+	return w.Flush()
+}
+
+func runGcExpired(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	fs := newFlagSet("gc-expired")
+	mode := fs.String("mode", cfg.Reaper.Mode, "purge deletes expired links, archive moves them to url_archive")
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *mode != service.ReaperModePurge && *mode != service.ReaperModeArchive {
+		return fmt.Errorf("unknown mode %q", *mode)
+	}
+
+	a, err := newApp(cfg, log)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	n, err := service.NewExpiredReaper(a.urlRepo, log, 0, *mode).Reap(ctx)
+	if err != nil {
+		return err
+	}
+	verb := "deleted"
+	if *mode == service.ReaperModeArchive {
+		verb = "archived"
+	}
+	fmt.Printf("%s %d expired links\n", verb, n)
+
+	return nil
+}
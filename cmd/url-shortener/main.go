@@ -2,52 +2,148 @@ package main
 
 import (
 	"awesomeProject/internal/config"
-	"awesomeProject/internal/http/handlers"
-	"awesomeProject/internal/http/middlewares"
-	"awesomeProject/internal/repositiries"
-	"awesomeProject/internal/service"
 	"awesomeProject/pkg/logger"
-	"awesomeProject/pkg/postgres"
 	"context"
-
-	"github.com/labstack/echo/v5"
-	"github.com/labstack/echo/v5/middleware"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 )
 
+type command struct {
+	name string
+	// args sketches the command line after the command name
+	args    string
+	summary string
+	run     func(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "", "run the HTTP server (the default)", runServe},
+		{"migrate", "[-dir path] [-steps n] up|down", "apply or revert schema migrations", runMigrate},
+		{"create", "[flags] <url>", "create a link and print its short URL", runCreate},
+		{"list", "[flags]", "list links", runList},
+		{"delete", "[-as name] <id>...", "delete links by id", runDelete},
+		{"import", "[-as name] [-dry-run] <file.csv|->", "create links from a CSV file", runImport},
+		{"export", "[flags]", "write links as csv, json or ndjson", runExport},
+		{"stats", "[flags] <alias>", "show click statistics of a link", runStats},
+		{"gc-expired", "[-mode purge|archive]", "remove expired links once", runGcExpired},
+		{"create-api-key", "[-role role] <user>", "issue an API key, creating the user if needed", runCreateApiKey},
+	}
+}
+
 func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage()
+		return
+	}
+	i := commandIndex(name)
+	if i < 0 {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+
 	cfg := config.MustLoad()
-	log := logger.NewLogger(cfg.Env)
-	log.Info("app initialized")
-	log.Debug("debug enabled")
+	var log *slog.Logger
+	if name == "serve" {
+		log = logger.NewLogger(cfg.Env)
+		log.Info("app initialized")
+		log.Debug("debug enabled")
+	} else {
+		// keep stdout for the command's output
+		log = logger.NewLoggerTo(cfg.Env, os.Stderr)
+	}
+
+	// the first SIGINT or SIGTERM cancels ctx so the command can wind down,
+	// a second one kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 
-	pool, err := postgres.NewPostgres(cfg.Postgres)
-	if err != nil {
-		log.Error("failed to connect to postgres", err)
+	err := commands[i].run(ctx, cfg, log, args)
+	stop()
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		os.Exit(1)
 	}
+}
 
-	repo := repositiries.NewUrlRepository(pool)
-	generator := service.NewAliasGenerator()
-	serv := service.NewUrlService(repo, generator, log, cfg.BaseUrl())
-	urlHandler := handlers.NewUrlHandler(serv)
+func commandIndex(name string) int {
+	for i, cmd := range commands {
+		if cmd.name == name {
+			return i
+		}
+	}
+	return -1
+}
 
-	_, cancel := context.WithCancel(context.Background())
-	defer cancel()
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [arguments]\n\ncommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-15s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun %s <command> -h for the flags of a command. CONFIG_PATH must name the config file.\n", os.Args[0])
+}
 
-	// echo
-	e := echo.New()
-	e.Use(middleware.RequestID())
-	e.Use(middlewares.RequestContext)
-	e.Use(middlewares.RequestLogger(log))
-	e.Use(middleware.Recover())
+// errUsage reports a command line error already explained to the user.
+var errUsage = errors.New("usage error")
 
-	// routes
-	e.POST("/url", urlHandler.SaveUrl)
-	e.GET("/list", urlHandler.ListUrls)
-	e.GET("/url/:id", urlHandler.Redirect)
-	e.PUT("/url", urlHandler.Update)
-	e.DELETE("/url/:id", urlHandler.Delete)
+// newFlagSet returns the flag set of the command called name; its usage
+// message shows the command's synopsis.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		cmd := commands[commandIndex(name)]
+		fmt.Fprintf(fs.Output(), "usage: %s %s %s\n\n%s\n", os.Args[0], cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
 
-	if err = e.Start(":8080"); err != nil {
-		log.Error("failed to start server", err)
+// parseFlags parses args into fs and checks the number of positional
+// arguments left, requiring at least min and at most max (-1 for any).
+func parseFlags(fs *flag.FlagSet, args []string, min, max int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
 	}
+	if fs.NArg() < min || (max >= 0 && fs.NArg() > max) {
+		fmt.Fprintln(fs.Output(), "wrong number of arguments")
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// stringsFlag collects the values of a flag given several times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
 }
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/http/handlers"
+	"awesomeProject/internal/http/middlewares"
+	"awesomeProject/internal/repositiries"
+	"awesomeProject/internal/service"
+	"awesomeProject/migrations"
+	"awesomeProject/pkg/migrate"
+	"context"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/labstack/echo/v5/middleware"
+)
+
+// idleTimeoutFactor scales HTTPServer.Timeout, which bounds reading and
+// writing a request, into how long an idle keep-alive connection is kept.
+const idleTimeoutFactor = 12
+
+func runServe(ctx context.Context, cfg *config.Config, log *slog.Logger, args []string) error {
+	if err := parseFlags(newFlagSet("serve"), args, 0, 0); err != nil {
+		return err
+	}
+
+	a, err := newApp(cfg, log)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	if cfg.AutoMigrate {
+		m, err := migrate.New(a.pool, migrations.FS, log)
+		if err != nil {
+			return err
+		}
+		if _, err := m.Up(ctx); err != nil {
+			return fmt.Errorf("failed to migrate: %w", err)
+		}
+	}
+
+	clicks := service.NewClickAggregator(a.urlRepo, log, cfg.Clicks.FlushInterval)
+	clickEvents := service.NewClickEventQueue(
+		a.clickRepo,
+		log,
+		cfg.Clicks.EventQueueSize,
+		cfg.Clicks.EventBatchSize,
+		cfg.Clicks.EventFlushInterval,
+	)
+	var auth service.Authenticator = service.NewAuthService(a.userRepo)
+	if cfg.Auth.Mode == "jwt" {
+		jwks, err := service.NewJwks(ctx, cfg.Auth.Jwt.Jwks, cfg.Auth.Jwt.JwksRefresh)
+		if err != nil {
+			return fmt.Errorf("failed to load jwks: %w", err)
+		}
+		auth = service.NewJwtAuthenticator(jwks, a.userRepo, service.JwtConfig{
+			Issuer:      cfg.Auth.Jwt.Issuer,
+			Audience:    cfg.Auth.Jwt.Audience,
+			OwnerClaim:  cfg.Auth.Jwt.OwnerClaim,
+			RolesClaim:  cfg.Auth.Jwt.RolesClaim,
+			Leeway:      cfg.Auth.Jwt.Leeway,
+			CreateUsers: cfg.Auth.Jwt.CreateUsers,
+		})
+	}
+	workspaceRepo := repositiries.NewWorkspaceRepository(a.pool)
+	workspaces := service.NewWorkspaceAccessPolicy(service.NewWorkspaceService(workspaceRepo, a.userRepo))
+	workspaceHandler := handlers.NewWorkspaceHandler(workspaces)
+	domainHandler := handlers.NewDomainHandler(
+		service.NewDomainAccessPolicy(service.NewDomainService(a.domainRepo, workspaceRepo)),
+	)
+	urlHandler := handlers.NewUrlHandler(a.urls, clicks, clickEvents)
+	statsHandler := handlers.NewStatsHandler(a.stats)
+
+	// the workers outlive ctx: they stop only once the server has drained,
+	// so that the clicks of the last requests are flushed too
+	workerCtx, stopWorkers := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopWorkers()
+
+	reaper := service.NewExpiredReaper(a.urlRepo, log, cfg.Reaper.Interval, cfg.Reaper.Mode)
+	reaperDone := make(chan struct{})
+	go func() {
+		defer close(reaperDone)
+		reaper.Run(workerCtx)
+	}()
+
+	clicksDone := make(chan struct{})
+	go func() {
+		defer close(clicksDone)
+		clicks.Run(workerCtx)
+	}()
+
+	clickEventsDone := make(chan struct{})
+	go func() {
+		defer close(clickEventsDone)
+		clickEvents.Run(workerCtx)
+	}()
+
+	// echo
+	e := echo.New()
+	e.IPExtractor = ipExtractor(cfg.HTTPServer.TrustedProxies)
+	e.Use(middleware.RequestID())
+	e.Use(middlewares.RequestContext)
+	e.Use(middlewares.RequestLogger(log))
+	e.Use(middleware.Recover())
+
+	// routes
+	api := e.Group("", middlewares.BearerAuth(auth), middlewares.Workspace(workspaces))
+	api.POST("/url", urlHandler.SaveUrl)
+	api.GET("/list", urlHandler.ListUrls)
+	api.GET("/url/search", urlHandler.Search)
+	bulkDeadline := middlewares.Deadline(cfg.HTTPServer.BulkTimeout)
+	api.POST("/url/bulk", urlHandler.SaveBulk, bulkDeadline)
+	api.PATCH("/url/bulk", urlHandler.UpdateBulk, bulkDeadline)
+	api.DELETE("/url/bulk", urlHandler.DeleteBulk, bulkDeadline)
+	api.POST("/url/import", urlHandler.Import, bulkDeadline)
+	api.GET("/url/export", urlHandler.Export)
+	api.PUT("/url", urlHandler.Update)
+	api.DELETE("/url/:id", urlHandler.Delete)
+	api.GET("/url/:alias/stats", statsHandler.Stats)
+	api.POST("/workspaces", workspaceHandler.Create)
+	api.GET("/workspaces", workspaceHandler.List)
+	api.POST("/workspaces/:name/members", workspaceHandler.AddMember)
+	api.POST("/domains", domainHandler.Create)
+	api.GET("/domains", domainHandler.List)
+	// the counters expose the command line and memory stats, admins only
+	e.GET("/debug/vars", echo.WrapHandler(expvar.Handler()),
+		middlewares.BearerAuth(auth), middlewares.RequireRole(user.RoleAdmin))
+	e.GET("/:alias", urlHandler.Redirect)
+
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.HTTPServer.Port),
+		Handler:           e,
+		ReadHeaderTimeout: cfg.HTTPServer.Timeout,
+		ReadTimeout:       cfg.HTTPServer.Timeout,
+		WriteTimeout:      cfg.HTTPServer.Timeout,
+		IdleTimeout:       idleTimeoutFactor * cfg.HTTPServer.Timeout,
+		ErrorLog:          slog.NewLogLogger(log.Handler(), slog.LevelError),
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Info("starting server", slog.String("addr", srv.Addr))
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErr:
+		err = fmt.Errorf("failed to start server: %w", err)
+	case <-ctx.Done():
+		log.Info("shutting down, draining requests")
+		// give a running bulk request the time it was promised
+		grace := max(cfg.HTTPServer.Timeout, cfg.HTTPServer.BulkTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error("failed to drain requests", slog.String("err", err.Error()))
+			srv.Close()
+		}
+		cancel()
+	}
+
+	// flush buffered clicks, then the deferred close releases the pool
+	stopWorkers()
+	<-reaperDone
+	<-clicksDone
+	<-clickEventsDone
+	log.Info("server stopped")
+
+	return err
+}
+
+// ipExtractor takes the client IP from X-Forwarded-For only when the request
+// comes from one of the trusted proxies, so clients cannot forge the address
+// stored with their clicks.
+func ipExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range trustedProxies {
+		// validated when the config was loaded
+		_, ipRange, _ := net.ParseCIDR(cidr)
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -4,6 +4,7 @@ import (
 	"awesomeProject/pkg/postgres"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"time"
 
@@ -14,12 +15,75 @@ type Config struct {
 	Env        string `yaml:"env" env-default:"local"`
 	HTTPServer `yaml:"http_server"`
 	Postgres   postgres.PGConfig `yaml:"postgres"`
+	Reaper     Reaper            `yaml:"reaper"`
+	Clicks     Clicks            `yaml:"clicks"`
+	Alias      Alias             `yaml:"alias"`
+	Dedupe     bool              `yaml:"dedupe" env-default:"false"`
+	Auth       Auth              `yaml:"auth"`
+
+	// AutoMigrate applies pending schema migrations when the server starts.
+	AutoMigrate bool `yaml:"auto_migrate" env-default:"false"`
 }
 
 type HTTPServer struct {
 	Port    int           `yaml:"port" env-default:"8080"`
 	Host    string        `yaml:"host" env-default:"localhost"`
 	Timeout time.Duration `yaml:"timeout" env-default:"5s"`
+	// DomainScheme is used for short links on registered domains, which are
+	// expected to sit behind a TLS terminating proxy.
+	DomainScheme string `yaml:"domain_scheme" env-default:"https"`
+	// BulkTimeout replaces Timeout for bulk requests and imports, which may
+	// upload and process thousands of links.
+	BulkTimeout time.Duration `yaml:"bulk_timeout" env-default:"2m"`
+	// TrustedProxies lists the CIDR ranges of the proxies whose
+	// X-Forwarded-For header is believed. With none, the client IP is the
+	// address of the connection.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+type Reaper struct {
+	Interval time.Duration `yaml:"interval" env-default:"1h"`
+	Mode     string        `yaml:"mode" env-default:"purge"`
+}
+
+type Clicks struct {
+	FlushInterval      time.Duration `yaml:"flush_interval" env-default:"5s"`
+	EventQueueSize     int           `yaml:"event_queue_size" env-default:"4096"`
+	EventBatchSize     int           `yaml:"event_batch_size" env-default:"256"`
+	EventFlushInterval time.Duration `yaml:"event_flush_interval" env-default:"1s"`
+}
+
+type Alias struct {
+	MinLength     int            `yaml:"min_length" env-default:"3"`
+	MaxLength     int            `yaml:"max_length" env-default:"32"`
+	Charset       string         `yaml:"charset" env-default:"abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"`
+	Reserved      []string       `yaml:"reserved" env-default:"url,list,api,health,workspaces,domains"`
+	CaseSensitive bool           `yaml:"case_sensitive" env-default:"true"`
+	Generator     AliasGenerator `yaml:"generator"`
+}
+
+type AliasGenerator struct {
+	Strategy  string `yaml:"strategy" env-default:"random"`
+	MinLength int    `yaml:"min_length" env-default:"6"`
+	Salt      string `yaml:"salt"`
+	Words     int    `yaml:"words" env-default:"3"`
+	BlockSize int64  `yaml:"block_size" env-default:"1000"`
+}
+
+type Auth struct {
+	Mode string `yaml:"mode" env-default:"api_key"`
+	Jwt  Jwt    `yaml:"jwt"`
+}
+
+type Jwt struct {
+	Jwks        string        `yaml:"jwks"`
+	JwksRefresh time.Duration `yaml:"jwks_refresh" env-default:"10m"`
+	Issuer      string        `yaml:"issuer"`
+	Audience    string        `yaml:"audience"`
+	OwnerClaim  string        `yaml:"owner_claim" env-default:"sub"`
+	RolesClaim  string        `yaml:"roles_claim" env-default:"roles"`
+	Leeway      time.Duration `yaml:"leeway" env-default:"1m"`
+	CreateUsers bool          `yaml:"create_users" env-default:"true"`
 }
 
 func MustLoad() *Config {
@@ -36,9 +100,39 @@ func MustLoad() *Config {
 	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
 		log.Fatal(err)
 	}
+	if err := cfg.validate(); err != nil {
+		log.Fatalf("invalid config %s: %v", configPath, err)
+	}
 	return &cfg
 }
 
-func (c *HTTPServer) BaseUrl() string {
+// validate rejects settings whose typos would otherwise silently pick
+// another behaviour.
+func (c *Config) validate() error {
+	switch c.Reaper.Mode {
+	case "purge", "archive":
+	default:
+		return fmt.Errorf("reaper.mode: unknown mode %q, want purge or archive", c.Reaper.Mode)
+	}
+	switch c.Auth.Mode {
+	case "api_key", "jwt":
+	default:
+		return fmt.Errorf("auth.mode: unknown mode %q, want api_key or jwt", c.Auth.Mode)
+	}
+	for _, cidr := range c.HTTPServer.TrustedProxies {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("http_server.trusted_proxies: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// BaseUrl returns the root short links on domain are built from. An empty
+// domain stands for the server's own host and port.
+func (c *HTTPServer) BaseUrl(domain string) string {
+	if domain != "" {
+		return fmt.Sprintf("%s://%s", c.DomainScheme, domain)
+	}
 	return fmt.Sprintf("http://%s:%d", c.Host, c.Port)
 }
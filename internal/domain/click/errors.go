@@ -0,0 +1,8 @@
+package click
+
+import "errors"
+
+var (
+	ErrInvalidRange    = errors.New("invalid range")
+	ErrInvalidInterval = errors.New("invalid interval")
+)
@@ -0,0 +1,12 @@
+package click
+
+import "time"
+
+type Event struct {
+	UrlId           int
+	ClickedAt       time.Time
+	Referrer        string
+	UserAgentFamily string
+	IpPrefix        string
+	RequestId       string
+}
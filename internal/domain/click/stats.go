@@ -0,0 +1,26 @@
+package click
+
+import "time"
+
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+)
+
+// Stats aggregates click events over a time range. Unique visitors are
+// approximated by distinct (ip prefix, user agent family) pairs, since only
+// anonymized data is stored.
+type Stats struct {
+	From           time.Time
+	To             time.Time
+	Interval       string
+	TotalClicks    int64
+	UniqueVisitors int64
+	Series         []Bucket
+}
+
+type Bucket struct {
+	Start          time.Time
+	Clicks         int64
+	UniqueVisitors int64
+}
@@ -0,0 +1,30 @@
+package url
+
+import "time"
+
+// Draft is a link to create in a bulk request, see Url for the fields.
+type Draft struct {
+	Host        string
+	OriginalUrl string
+	Alias       string
+	ExpiresAt   time.Time
+	Details
+}
+
+// Change is an update of one link in a bulk request. Like a single update it
+// always replaces the destination and leaves a blank Alias or zero ExpiresAt
+// unchanged.
+type Change struct {
+	Id        int
+	NewUrl    string
+	Alias     string
+	ExpiresAt time.Time
+	Details   DetailsPatch
+}
+
+// ItemResult is the outcome of one item of a bulk request. Url is the stored
+// link for successful creations.
+type ItemResult struct {
+	Url Url
+	Err error
+}
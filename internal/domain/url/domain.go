@@ -0,0 +1,19 @@
+package url
+
+import "time"
+
+// Domain is a host short links are served on in addition to the default one.
+type Domain struct {
+	Id   int
+	Host string
+	// WorkspaceId restricts the domain to one workspace's links; 0 means any
+	// caller may use it.
+	WorkspaceId int
+	CreatedAt   time.Time
+}
+
+// UsableIn reports whether links of workspaceId (0 outside a workspace) may be
+// created on the domain.
+func (d Domain) UsableIn(workspaceId int) bool {
+	return d.WorkspaceId == 0 || d.WorkspaceId == workspaceId
+}
@@ -6,4 +6,21 @@ var (
 	ErrNotFound     = errors.New("not found")
 	ErrAliasTaken   = errors.New("alias already taken")
 	ErrInvalidAlias = errors.New("invalid alias")
+	ErrInvalidUrl   = errors.New("invalid url")
+	ErrDuplicateUrl = errors.New("url already shortened")
+
+	ErrAliasSpaceExhausted = errors.New("alias space exhausted")
+	ErrExpired             = errors.New("url expired")
+	ErrInvalidExpiry       = errors.New("invalid expiry")
+
+	ErrUnknownDomain = errors.New("unknown domain")
+	ErrDomainTaken   = errors.New("domain already registered")
+	ErrInvalidDomain = errors.New("invalid domain")
+
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidFilter = errors.New("invalid filter")
+
+	ErrInvalidDetails = errors.New("invalid details")
+
+	ErrBatchTooLarge = errors.New("too many items in batch")
 )
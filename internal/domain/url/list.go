@@ -0,0 +1,51 @@
+package url
+
+import "time"
+
+// Sort orders of a link listing; ties are broken by id.
+const (
+	SortCreated = "created"
+	SortClicks  = "clicks"
+)
+
+// States a listing can be narrowed to.
+const (
+	StateActive  = "active"
+	StateExpired = "expired"
+)
+
+// ListFilter narrows a link listing. Zero fields do not filter.
+type ListFilter struct {
+	AliasPrefix string
+	// Destination matches a case-insensitive substring of the original url.
+	Destination string
+	// CreatedFrom and CreatedTo bound the creation time to [from, to).
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Tags keeps the links carrying every one of them.
+	Tags []string
+	// State keeps only active or only expired links, judged as of Now.
+	State string
+	Now   time.Time
+}
+
+type ListQuery struct {
+	ListFilter
+	Sort  string
+	Desc  bool
+	Limit int
+}
+
+// Cursor is the position of the last link of a page in the listing's sort
+// order.
+type Cursor struct {
+	CreatedAt time.Time
+	Clicks    int
+	Id        int
+}
+
+type Page struct {
+	Urls []Url
+	// NextCursor resumes the listing after Urls, empty on the last page.
+	NextCursor string
+}
@@ -9,4 +9,43 @@ type Url struct {
 	CreatedAt   time.Time
 	ExpiresAt   time.Time
 	Clicks      int
+	OwnerId     int
+	WorkspaceId int
+	// DomainId and Domain name the host the link is served on; aliases are
+	// unique per domain. Both are zero for the default host.
+	DomainId int
+	Domain   string
+	Details
+}
+
+// Details describe a link for the people managing it; they play no part in
+// redirects.
+type Details struct {
+	Title       string
+	Description string
+	Tags        []string
+	// Metadata holds arbitrary JSON object fields.
+	Metadata map[string]any
+}
+
+func (d Details) IsZero() bool {
+	return d.Title == "" && d.Description == "" && len(d.Tags) == 0 && len(d.Metadata) == 0
+}
+
+// DetailsPatch replaces the Details fields that are not nil.
+type DetailsPatch struct {
+	Title       *string
+	Description *string
+	Tags        *[]string
+	Metadata    map[string]any
+}
+
+func (p DetailsPatch) IsZero() bool {
+	return p.Title == nil && p.Description == nil && p.Tags == nil && p.Metadata == nil
+}
+
+// IsExpired reports whether the link has an expiry that is not after now.
+// A zero ExpiresAt means the link never expires.
+func (u Url) IsExpired(now time.Time) bool {
+	return !u.ExpiresAt.IsZero() && !u.ExpiresAt.After(now)
 }
@@ -0,0 +1,22 @@
+package url
+
+// Scope selects the links a caller may see or change. Inside a workspace
+// that is the workspace's links, narrowed to one owner's when OwnerId is set
+// as well; outside one, the owner's personal links. The zero Scope is
+// unrestricted.
+type Scope struct {
+	OwnerId     int
+	WorkspaceId int
+}
+
+// Contains reports whether u is in the scope.
+func (s Scope) Contains(u Url) bool {
+	switch {
+	case s.WorkspaceId != 0:
+		return u.WorkspaceId == s.WorkspaceId && (s.OwnerId == 0 || u.OwnerId == s.OwnerId)
+	case s.OwnerId != 0:
+		return u.OwnerId == s.OwnerId && u.WorkspaceId == 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,11 @@
+package url
+
+// SearchHit is a link matching a search, with its relevance and the matched
+// fields with the matching words highlighted.
+type SearchHit struct {
+	Url
+	Rank float64
+	// Highlights maps a field name to its HTML-escaped value with matches
+	// wrapped in <mark> tags. Fields without a match are left out.
+	Highlights map[string]string
+}
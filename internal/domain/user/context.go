@@ -0,0 +1,16 @@
+package user
+
+import "context"
+
+type ctxKey struct{}
+
+// WithUser stores the authenticated caller in ctx.
+func WithUser(ctx context.Context, u User) context.Context {
+	return context.WithValue(ctx, ctxKey{}, u)
+}
+
+// FromContext returns the authenticated caller, if any.
+func FromContext(ctx context.Context) (User, bool) {
+	u, ok := ctx.Value(ctxKey{}).(User)
+	return u, ok
+}
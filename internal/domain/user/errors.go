@@ -0,0 +1,15 @@
+package user
+
+import "errors"
+
+var (
+	ErrNotFound     = errors.New("user not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrInvalidRole  = errors.New("invalid role")
+	ErrNameTaken    = errors.New("user name already taken")
+
+	// ErrIdentityTaken reports a token issuer and subject already bound to a
+	// user.
+	ErrIdentityTaken = errors.New("identity already taken")
+)
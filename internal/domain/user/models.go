@@ -0,0 +1,19 @@
+package user
+
+import "time"
+
+type User struct {
+	Id        int
+	Name      string
+	CreatedAt time.Time
+	// Roles of API key callers come from the users table; JWT callers take
+	// them from the token instead.
+	Roles []string
+}
+
+type ApiKey struct {
+	Id        int
+	UserId    int
+	Prefix    string
+	CreatedAt time.Time
+}
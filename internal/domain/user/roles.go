@@ -0,0 +1,25 @@
+package user
+
+const (
+	// RoleViewer may list links and read their stats.
+	RoleViewer = "viewer"
+	// RoleEditor may additionally create, update and delete its own links.
+	RoleEditor = "editor"
+	// RoleAdmin manages every owner's links and may use reserved aliases.
+	RoleAdmin = "admin"
+)
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	return role == RoleViewer || role == RoleEditor || role == RoleAdmin
+}
+
+// HasRole reports whether the user was granted role.
+func (u User) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
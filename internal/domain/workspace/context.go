@@ -0,0 +1,16 @@
+package workspace
+
+import "context"
+
+type ctxKey struct{}
+
+// WithWorkspace stores the workspace the caller is acting in.
+func WithWorkspace(ctx context.Context, w Workspace) context.Context {
+	return context.WithValue(ctx, ctxKey{}, w)
+}
+
+// FromContext returns the workspace the caller is acting in, if any.
+func FromContext(ctx context.Context) (Workspace, bool) {
+	w, ok := ctx.Value(ctxKey{}).(Workspace)
+	return w, ok
+}
@@ -0,0 +1,9 @@
+package workspace
+
+import "errors"
+
+var (
+	ErrNotFound  = errors.New("workspace not found")
+	ErrNameTaken = errors.New("workspace name already taken")
+	ErrInvalid   = errors.New("invalid workspace")
+)
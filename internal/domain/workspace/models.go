@@ -0,0 +1,13 @@
+package workspace
+
+import "time"
+
+// Workspace groups users sharing a set of links.
+type Workspace struct {
+	Id   int
+	Name string
+	// Domain is the host the workspace's links are created on unless another
+	// one is asked for; empty for the default host.
+	Domain    string
+	CreatedAt time.Time
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/http/schemes"
+	"awesomeProject/internal/service"
+	resp "awesomeProject/pkg/api/response"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+)
+
+// MIMEApplicationNDJSON marks request bodies holding one JSON item per line.
+const MIMEApplicationNDJSON = "application/x-ndjson"
+
+// maxBulkBodyBytes bounds the body of a bulk request, generous for
+// service.MaxBulkItems items.
+const maxBulkBodyBytes = 32 << 20
+
+// SaveBulk serves POST /url/bulk with a JSON array or NDJSON stream of
+// UrlCreateSchema items.
+func (h *UrlHandler) SaveBulk(c *echo.Context) error {
+	items, err := decodeItems[schemes.UrlCreateSchema](c)
+	if err != nil {
+		return decodeError(c, err)
+	}
+
+	// items with an unusable expiry never reach the service
+	failed := make([]error, len(items))
+	var drafts []url.Draft
+	var draftIdx []int
+	for i, item := range items {
+		expiresAt, err := parseExpiry(item.UrlExpirySchema)
+		if err != nil {
+			failed[i] = err
+			continue
+		}
+		drafts = append(drafts, url.Draft{
+			Host:        item.Domain,
+			OriginalUrl: item.OriginalUrl,
+			Alias:       item.Alias,
+			ExpiresAt:   expiresAt,
+			Details: url.Details{
+				Title:       item.Title,
+				Description: item.Description,
+				Tags:        item.Tags,
+				Metadata:    item.Metadata,
+			},
+		})
+		draftIdx = append(draftIdx, i)
+	}
+
+	results, err := h.serv.SaveMany(c.Request().Context(), drafts)
+	if err != nil {
+		return bulkError(c, err)
+	}
+
+	body := make([]schemes.UrlBulkItemSchema, len(items))
+	for i, err := range failed {
+		body[i] = bulkItem(i, 0, err)
+	}
+	for j, result := range results {
+		i := draftIdx[j]
+		body[i] = bulkItem(i, result.Url.Id, result.Err)
+		if result.Err == nil {
+			u := h.toSchema(result.Url)
+			body[i].Url = &u
+		}
+	}
+
+	return c.JSON(http.StatusOK, bulkResponse(body))
+}
+
+// UpdateBulk serves PATCH /url/bulk with a JSON array or NDJSON stream of
+// UrlUpdateSchema items, each applied like PUT /url.
+func (h *UrlHandler) UpdateBulk(c *echo.Context) error {
+	items, err := decodeItems[schemes.UrlUpdateSchema](c)
+	if err != nil {
+		return decodeError(c, err)
+	}
+
+	failed := make([]error, len(items))
+	var changes []url.Change
+	var changeIdx []int
+	for i, item := range items {
+		expiresAt, err := parseExpiry(item.UrlExpirySchema)
+		if err != nil {
+			failed[i] = err
+			continue
+		}
+		changes = append(changes, url.Change{
+			Id:        item.Id,
+			NewUrl:    item.NewUrl,
+			Alias:     item.Alias,
+			ExpiresAt: expiresAt,
+			Details: url.DetailsPatch{
+				Title:       item.Title,
+				Description: item.Description,
+				Tags:        item.Tags,
+				Metadata:    item.Metadata,
+			},
+		})
+		changeIdx = append(changeIdx, i)
+	}
+
+	results, err := h.serv.UpdateMany(c.Request().Context(), changes)
+	if err != nil {
+		return bulkError(c, err)
+	}
+
+	body := make([]schemes.UrlBulkItemSchema, len(items))
+	for i, err := range failed {
+		body[i] = bulkItem(i, items[i].Id, err)
+	}
+	for j, result := range results {
+		i := changeIdx[j]
+		body[i] = bulkItem(i, items[i].Id, result.Err)
+	}
+
+	return c.JSON(http.StatusOK, bulkResponse(body))
+}
+
+// DeleteBulk serves DELETE /url/bulk with a JSON array or NDJSON stream of
+// UrlDeleteSchema items.
+func (h *UrlHandler) DeleteBulk(c *echo.Context) error {
+	items, err := decodeItems[schemes.UrlDeleteSchema](c)
+	if err != nil {
+		return decodeError(c, err)
+	}
+
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.Id
+	}
+
+	results, err := h.serv.DeleteMany(c.Request().Context(), ids)
+	if err != nil {
+		return bulkError(c, err)
+	}
+
+	body := make([]schemes.UrlBulkItemSchema, len(items))
+	for i, result := range results {
+		body[i] = bulkItem(i, ids[i], result.Err)
+	}
+
+	return c.JSON(http.StatusOK, bulkResponse(body))
+}
+
+// decodeItems reads the request body as a JSON array, or as a stream of JSON
+// values for NDJSON requests. It stops at the first item over
+// service.MaxBulkItems or at maxBulkBodyBytes, so an oversized body is never
+// held in memory.
+func decodeItems[T any](c *echo.Context) ([]T, error) {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxBulkBodyBytes)
+	dec := json.NewDecoder(req.Body)
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	ndjson := mediaType == MIMEApplicationNDJSON || mediaType == "application/ndjson"
+	if !ndjson {
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return nil, fmt.Errorf("invalid body: %w", decodeErr(err, "expected a JSON array"))
+		}
+	}
+
+	var items []T
+	for ndjson || dec.More() {
+		var item T
+		err := dec.Decode(&item)
+		if ndjson && errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid item %d: %w", len(items), decodeErr(err, ""))
+		}
+		if len(items) == service.MaxBulkItems {
+			return nil, fmt.Errorf("%w: at most %d items are allowed", url.ErrBatchTooLarge, service.MaxBulkItems)
+		}
+		items = append(items, item)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("invalid body: %w", decodeErr(err, ""))
+	}
+
+	return items, nil
+}
+
+// decodeErr reports a body over maxBulkBodyBytes as ErrBatchTooLarge and an
+// unexpected token, for which err is nil, as msg.
+func decodeErr(err error, msg string) error {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return fmt.Errorf("%w: body exceeds %d bytes", url.ErrBatchTooLarge, tooLarge.Limit)
+	case err == nil:
+		return errors.New(msg)
+	case errors.Is(err, io.EOF):
+		return io.ErrUnexpectedEOF
+	default:
+		return err
+	}
+}
+
+// decodeError answers a request whose items could not be read.
+func decodeError(c *echo.Context, err error) error {
+	if errors.Is(err, url.ErrBatchTooLarge) {
+		return c.JSON(http.StatusRequestEntityTooLarge, resp.Error(err.Error()))
+	}
+	return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+}
+
+func bulkItem(index, id int, err error) schemes.UrlBulkItemSchema {
+	item := schemes.UrlBulkItemSchema{Index: index, Id: id, Response: resp.OK()}
+	if err != nil {
+		item.Response = resp.Error(err.Error())
+	}
+	return item
+}
+
+func bulkResponse(items []schemes.UrlBulkItemSchema) schemes.UrlBulkSchema {
+	body := schemes.UrlBulkSchema{Results: items, Response: resp.OK()}
+	for _, item := range items {
+		if item.Status == resp.StatusOK {
+			body.Succeeded++
+		} else {
+			body.Failed++
+		}
+	}
+	return body
+}
+
+func bulkError(c *echo.Context, err error) error {
+	switch {
+	case errors.Is(err, url.ErrBatchTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, resp.Error(err.Error()))
+	case errors.Is(err, url.ErrAliasTaken):
+		// an alias was claimed concurrently and nothing was applied, the
+		// batch can be retried as is
+		return c.JSON(http.StatusConflict, resp.Error(err.Error()))
+	case errors.Is(err, user.ErrForbidden):
+		return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+	default:
+		return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/domain/workspace"
+	"awesomeProject/internal/http/schemes"
+	"awesomeProject/internal/service"
+	resp "awesomeProject/pkg/api/response"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+)
+
+type DomainHandler struct {
+	serv service.DomainService
+}
+
+func NewDomainHandler(serv service.DomainService) *DomainHandler {
+	return &DomainHandler{serv: serv}
+}
+
+func (h *DomainHandler) Create(c *echo.Context) error {
+	var req schemes.DomainCreateSchema
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+	}
+
+	d, err := h.serv.Create(c.Request().Context(), req.Host, req.Workspace)
+	if err != nil {
+		switch {
+		case errors.Is(err, url.ErrInvalidDomain):
+			return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+		case errors.Is(err, url.ErrDomainTaken):
+			return c.JSON(http.StatusConflict, resp.Error("domain already registered"))
+		case errors.Is(err, workspace.ErrNotFound):
+			return c.JSON(http.StatusNotFound, resp.Error("workspace not found"))
+		case errors.Is(err, user.ErrForbidden):
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+		default:
+			return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+		}
+	}
+
+	return c.JSON(http.StatusCreated, toDomainSchema(d))
+}
+
+func (h *DomainHandler) List(c *echo.Context) error {
+	domains, err := h.serv.List(c.Request().Context())
+	if err != nil {
+		if errors.Is(err, user.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+		}
+		return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+	}
+
+	body := make([]schemes.DomainGetSchema, len(domains))
+	for idx, d := range domains {
+		body[idx] = toDomainSchema(d)
+	}
+
+	return c.JSON(http.StatusOK, body)
+}
+
+func toDomainSchema(d url.Domain) schemes.DomainGetSchema {
+	return schemes.DomainGetSchema{
+		Id:          d.Id,
+		Host:        d.Host,
+		WorkspaceId: d.WorkspaceId,
+		CreatedAt:   d.CreatedAt,
+		Response:    resp.OK(),
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/transfer"
+	resp "awesomeProject/pkg/api/response"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+// exportFlushEvery is how many rows are written between flushes of an export
+// to the client.
+const exportFlushEvery = 100
+
+// exportContentTypes maps the formats of GET /url/export to content types.
+var exportContentTypes = map[string]string{
+	transfer.FormatCSV:    "text/csv; charset=utf-8",
+	transfer.FormatJSON:   echo.MIMEApplicationJSON,
+	transfer.FormatNDJSON: MIMEApplicationNDJSON,
+}
+
+// Export serves GET /url/export?format=csv|json|ndjson with the filters of
+// GET /list, streaming the caller's links oldest first; csv is the default.
+// Once the first row is sent a failure can only cut the download short,
+// which leaves json output without its closing bracket.
+func (h *UrlHandler) Export(c *echo.Context) error {
+	format := c.QueryParamOr("format", transfer.FormatCSV)
+	w := c.Response()
+	enc, err := transfer.NewEncoder(format, w)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, resp.Error("unknown format "+strconv.Quote(format)))
+	}
+
+	f, err := parseListFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+	}
+
+	rc := http.NewResponseController(w)
+	started := false
+	start := func() error {
+		started = true
+		// a large export may outlast the server's write timeout; lifting the
+		// deadline fails harmlessly on writers without one
+		_ = rc.SetWriteDeadline(time.Time{})
+		w.Header().Set(echo.HeaderContentType, exportContentTypes[format])
+		w.Header().Set(echo.HeaderContentDisposition, `attachment; filename="links.`+format+`"`)
+		w.WriteHeader(http.StatusOK)
+		return enc.Begin()
+	}
+
+	rows := 0
+	err = h.serv.Export(c.Request().Context(), f, func(u url.Url) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(transfer.NewRecord(u, h.serv.ShortUrl(u))); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery != 0 {
+			return nil
+		}
+		if err := enc.Flush(); err != nil {
+			return err
+		}
+		return rc.Flush()
+	})
+	if err != nil {
+		if started {
+			return err
+		}
+		switch {
+		case errors.Is(err, url.ErrInvalidFilter):
+			return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+		case errors.Is(err, user.ErrForbidden):
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+		default:
+			return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+		}
+	}
+
+	if !started {
+		if err := start(); err != nil {
+			return err
+		}
+	}
+	return enc.End()
+}
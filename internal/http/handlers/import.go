@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/http/schemes"
+	"awesomeProject/internal/service"
+	"awesomeProject/internal/transfer"
+	resp "awesomeProject/pkg/api/response"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+)
+
+// Import serves POST /url/import?dry_run= with a CSV file in the format of
+// transfer.ReadCSV, sent as the body or as the "file" field of a multipart
+// form. A dry run validates the rows without creating links.
+func (h *UrlHandler) Import(c *echo.Context) error {
+	dryRun, err := echo.QueryParamOr[bool](c, "dry_run", false)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, resp.Error("invalid dry_run"))
+	}
+
+	file, err := importFile(c)
+	if err != nil {
+		return decodeError(c, decodeErr(err, ""))
+	}
+	defer file.Close()
+
+	rows, err := transfer.ReadCSV(file, service.MaxBulkItems)
+	if err != nil {
+		return decodeError(c, decodeErr(err, ""))
+	}
+
+	var drafts []url.Draft
+	var draftIdx []int
+	for i, row := range rows {
+		if row.Err == nil {
+			drafts = append(drafts, row.Draft)
+			draftIdx = append(draftIdx, i)
+		}
+	}
+
+	ctx := c.Request().Context()
+	var results []url.ItemResult
+	if dryRun {
+		results, err = h.serv.CheckMany(ctx, drafts)
+	} else {
+		results, err = h.serv.SaveMany(ctx, drafts)
+	}
+	if err != nil {
+		return bulkError(c, err)
+	}
+
+	body := schemes.UrlImportSchema{
+		DryRun:   dryRun,
+		Results:  make([]schemes.UrlImportRowSchema, len(rows)),
+		Response: resp.OK(),
+	}
+	for i, row := range rows {
+		body.Results[i] = importResult(row.Line, row.Err)
+	}
+	for j, result := range results {
+		i := draftIdx[j]
+		body.Results[i] = importResult(rows[i].Line, result.Err)
+		// links checked in a dry run have no id or generated alias yet
+		if result.Err == nil && !dryRun {
+			u := h.toSchema(result.Url)
+			body.Results[i].Url = &u
+		}
+	}
+	for _, row := range body.Results {
+		if row.Status == resp.StatusOK {
+			body.Succeeded++
+		} else {
+			body.Failed++
+		}
+	}
+
+	return c.JSON(http.StatusOK, body)
+}
+
+// importFile returns the uploaded CSV file, cut off at maxBulkBodyBytes like
+// the body of a bulk request.
+func importFile(c *echo.Context) (io.ReadCloser, error) {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxBulkBodyBytes)
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	if mediaType != echo.MIMEMultipartForm {
+		return req.Body, nil
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("missing file: %w", err)
+	}
+	return header.Open()
+}
+
+func importResult(line int, err error) schemes.UrlImportRowSchema {
+	row := schemes.UrlImportRowSchema{Line: line, Response: resp.OK()}
+	if err != nil {
+		row.Response = resp.Error(err.Error())
+	}
+	return row
+}
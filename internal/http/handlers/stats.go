@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"awesomeProject/internal/domain/click"
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/http/schemes"
+	"awesomeProject/internal/service"
+	resp "awesomeProject/pkg/api/response"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+const defaultStatsRange = 7 * 24 * time.Hour
+
+type StatsHandler struct {
+	serv service.StatsService
+}
+
+func NewStatsHandler(serv service.StatsService) *StatsHandler {
+	return &StatsHandler{serv: serv}
+}
+
+// Stats serves GET /url/:alias/stats?from=&to=&interval=hour|day&domain=.
+// from/to are RFC 3339 timestamps and default to the last seven days; domain
+// defaults to the workspace's default domain.
+func (h *StatsHandler) Stats(c *echo.Context) error {
+	alias := c.Param("alias")
+
+	to := time.Now()
+	if v := c.QueryParam("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, resp.Error("invalid to: "+err.Error()))
+		}
+		to = t
+	}
+
+	from := to.Add(-defaultStatsRange)
+	if v := c.QueryParam("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, resp.Error("invalid from: "+err.Error()))
+		}
+		from = t
+	}
+
+	interval := c.QueryParamOr("interval", click.IntervalDay)
+
+	stats, err := h.serv.Stats(c.Request().Context(), c.QueryParam("domain"), alias, from, to, interval)
+	if err != nil {
+		switch {
+		case errors.Is(err, url.ErrNotFound):
+			return c.JSON(http.StatusNotFound, resp.Error("url not found"))
+		case errors.Is(err, click.ErrInvalidInterval):
+			return c.JSON(http.StatusBadRequest, resp.Error("interval must be hour or day"))
+		case errors.Is(err, click.ErrInvalidRange):
+			return c.JSON(http.StatusBadRequest, resp.Error("invalid or too large range"))
+		case errors.Is(err, user.ErrForbidden):
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+		default:
+			return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+		}
+	}
+
+	body := schemes.StatsGetSchema{
+		Alias:          alias,
+		From:           stats.From,
+		To:             stats.To,
+		Interval:       stats.Interval,
+		TotalClicks:    stats.TotalClicks,
+		UniqueVisitors: stats.UniqueVisitors,
+		Series:         make([]schemes.StatsBucketSchema, len(stats.Series)),
+		Response:       resp.OK(),
+	}
+	for idx, b := range stats.Series {
+		body.Series[idx] = schemes.StatsBucketSchema{
+			Start:          b.Start,
+			Clicks:         b.Clicks,
+			UniqueVisitors: b.UniqueVisitors,
+		}
+	}
+
+	return c.JSON(http.StatusOK, body)
+}
@@ -2,87 +2,234 @@ package handlers
 
 import (
 	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/user"
 	"awesomeProject/internal/http/schemes"
 	"awesomeProject/internal/service"
+	resp "awesomeProject/pkg/api/response"
+	"awesomeProject/pkg/logger"
 	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v5"
 )
 
 type UrlHandler struct {
-	serv service.UrlService
+	serv   service.UrlService
+	clicks service.ClickCounter
+	events service.ClickRecorder
 }
 
-func NewUrlHandler(serv service.UrlService) *UrlHandler {
-	return &UrlHandler{serv: serv}
+func NewUrlHandler(
+	serv service.UrlService,
+	clicks service.ClickCounter,
+	events service.ClickRecorder,
+) *UrlHandler {
+	return &UrlHandler{serv: serv, clicks: clicks, events: events}
 }
 
 func (h *UrlHandler) SaveUrl(c *echo.Context) error {
 	var req schemes.UrlCreateSchema
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
 	}
 
-	err := h.serv.Save(c.Request().Context(), req.OriginalUrl, req.Alias)
+	expiresAt, err := parseExpiry(req.UrlExpirySchema)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+	}
+
+	u, err := h.serv.Save(
+		c.Request().Context(),
+		req.Domain, req.OriginalUrl, req.Alias,
+		expiresAt,
+		url.Details{
+			Title:       req.Title,
+			Description: req.Description,
+			Tags:        req.Tags,
+			Metadata:    req.Metadata,
+		},
+	)
 	if err != nil {
 		switch {
 		case errors.Is(err, url.ErrAliasTaken):
 			return c.JSON(
 				http.StatusConflict,
-				map[string]string{"error": "alias already taken"},
+				resp.Error("alias already taken"),
 			)
+		case errors.Is(err, url.ErrInvalidExpiry):
+			return c.JSON(
+				http.StatusBadRequest,
+				resp.Error("expiry must be in the future"),
+			)
+		case errors.Is(err, url.ErrInvalidUrl), errors.Is(err, url.ErrInvalidAlias),
+			errors.Is(err, url.ErrInvalidDetails):
+			return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+		case errors.Is(err, url.ErrUnknownDomain):
+			return c.JSON(http.StatusBadRequest, resp.Error("unknown domain"))
+		case errors.Is(err, url.ErrAliasSpaceExhausted):
+			return c.JSON(
+				http.StatusServiceUnavailable,
+				resp.Error("could not allocate an alias, try again or pick one"),
+			)
+		case errors.Is(err, user.ErrForbidden):
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
 		default:
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
 		}
 	}
 
-	return c.NoContent(http.StatusCreated)
+	body := h.toSchema(u)
+	c.Response().Header().Set(echo.HeaderLocation, body.ShortUrl)
+
+	return c.JSON(http.StatusCreated, body)
 }
 
+// ListUrls serves GET /list?limit=&cursor=&sort=created|clicks&alias_prefix=
+// &destination=&created_from=&created_to=&state=active|expired&tag=. A leading
+// "-" on sort reverses the order; created_from/created_to are RFC 3339
+// timestamps; tag may repeat to require several tags.
 func (h *UrlHandler) ListUrls(c *echo.Context) error {
-	urls, err := h.serv.List(c.Request().Context())
+	q, err := parseListQuery(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
 	}
 
-	resp := make([]schemes.UrlGetSchema, len(urls))
-	for idx, u := range urls {
-		resp[idx] = schemes.UrlGetSchema{
-			Id: u.Id,
-			UrlBaseSchema: schemes.UrlBaseSchema{
-				OriginalUrl: u.OriginalUrl,
-				Alias:       u.Alias,
-			},
+	page, err := h.serv.List(c.Request().Context(), q, c.QueryParam("cursor"))
+	if err != nil {
+		switch {
+		case errors.Is(err, url.ErrInvalidCursor), errors.Is(err, url.ErrInvalidFilter):
+			return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+		case errors.Is(err, user.ErrForbidden):
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+		default:
+			return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
 		}
 	}
 
-	return c.JSON(http.StatusOK, resp)
+	body := schemes.UrlListSchema{
+		Urls:       make([]schemes.UrlGetSchema, len(page.Urls)),
+		NextCursor: page.NextCursor,
+		Response:   resp.OK(),
+	}
+	for idx, u := range page.Urls {
+		body.Urls[idx] = h.toSchema(u)
+	}
+
+	return c.JSON(http.StatusOK, body)
 }
 
-func (h *UrlHandler) Redirect(c *echo.Context) error {
-	id, err := echo.PathParam[int](c, "id")
+// Search serves GET /url/search?q=&limit=.
+func (h *UrlHandler) Search(c *echo.Context) error {
+	limit, err := echo.QueryParamOr[int](c, "limit", 0)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, resp.Error("invalid limit"))
 	}
 
-	u, err := h.serv.Get(c.Request().Context(), id)
+	hits, err := h.serv.Search(c.Request().Context(), c.QueryParam("q"), limit)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		switch {
+		case errors.Is(err, url.ErrInvalidFilter):
+			return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+		case errors.Is(err, user.ErrForbidden):
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+		default:
+			return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+		}
 	}
 
+	body := schemes.UrlSearchSchema{
+		Results:  make([]schemes.UrlSearchHitSchema, len(hits)),
+		Response: resp.OK(),
+	}
+	for idx, hit := range hits {
+		body.Results[idx] = schemes.UrlSearchHitSchema{
+			UrlGetSchema: h.toSchema(hit.Url),
+			Rank:         hit.Rank,
+			Highlights:   hit.Highlights,
+		}
+	}
+
+	return c.JSON(http.StatusOK, body)
+}
+
+func (h *UrlHandler) Redirect(c *echo.Context) error {
+	alias := c.Param("alias")
+	if alias == "" {
+		return c.JSON(http.StatusBadRequest, resp.Error("alias is required"))
+	}
+
+	u, err := h.serv.GetByAlias(c.Request().Context(), c.Request().Host, alias)
+	if err != nil {
+		switch {
+		case errors.Is(err, url.ErrNotFound):
+			return c.JSON(http.StatusNotFound, resp.Error("url not found"))
+		case errors.Is(err, url.ErrExpired):
+			return c.JSON(http.StatusGone, resp.Error("url expired"))
+		default:
+			return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+		}
+	}
+
+	req := c.Request()
+	h.clicks.Add(u.Id)
+	h.events.Record(service.NewClickEvent(
+		u.Id,
+		req.Referer(),
+		req.UserAgent(),
+		c.RealIP(),
+		logger.RequestIDFromContext(req.Context()),
+		time.Now(),
+	))
+
 	return c.Redirect(http.StatusFound, u.OriginalUrl)
 }
 
 func (h *UrlHandler) Update(c *echo.Context) error {
 	var req schemes.UrlUpdateSchema
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
 	}
 
-	err := h.serv.Update(c.Request().Context(), req.Id, req.NewUrl, req.Alias)
+	expiresAt, err := parseExpiry(req.UrlExpirySchema)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+	}
+
+	err = h.serv.Update(
+		c.Request().Context(),
+		req.Id, req.NewUrl, req.Alias,
+		expiresAt,
+		url.DetailsPatch{
+			Title:       req.Title,
+			Description: req.Description,
+			Tags:        req.Tags,
+			Metadata:    req.Metadata,
+		},
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, url.ErrAliasTaken):
+			return c.JSON(
+				http.StatusConflict,
+				resp.Error("alias already taken"),
+			)
+		case errors.Is(err, url.ErrInvalidExpiry):
+			return c.JSON(
+				http.StatusBadRequest,
+				resp.Error("expiry must be in the future"),
+			)
+		case errors.Is(err, url.ErrInvalidUrl), errors.Is(err, url.ErrInvalidAlias),
+			errors.Is(err, url.ErrInvalidDetails):
+			return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+		case errors.Is(err, url.ErrNotFound):
+			return c.JSON(http.StatusNotFound, resp.Error("url not found"))
+		case errors.Is(err, user.ErrForbidden):
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+		default:
+			return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+		}
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -90,10 +237,117 @@ func (h *UrlHandler) Update(c *echo.Context) error {
 
 func (h *UrlHandler) Delete(c *echo.Context) error {
 	id, err := echo.PathParam[int](c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+	}
+
 	err = h.serv.Delete(c.Request().Context(), id)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		switch {
+		case errors.Is(err, url.ErrNotFound):
+			return c.JSON(http.StatusNotFound, resp.Error("url not found"))
+		case errors.Is(err, user.ErrForbidden):
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+		default:
+			return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+		}
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (h *UrlHandler) toSchema(u url.Url) schemes.UrlGetSchema {
+	return schemes.UrlGetSchema{
+		Id: u.Id,
+		UrlBaseSchema: schemes.UrlBaseSchema{
+			OriginalUrl: u.OriginalUrl,
+			Alias:       u.Alias,
+		},
+		Domain:    u.Domain,
+		ShortUrl:  h.serv.ShortUrl(u),
+		CreatedAt: u.CreatedAt,
+		Clicks:    u.Clicks,
+		ExpiresAt: expiryOrNil(u.ExpiresAt),
+		UrlDetailsSchema: schemes.UrlDetailsSchema{
+			Title:       u.Title,
+			Description: u.Description,
+			Tags:        u.Tags,
+			Metadata:    u.Metadata,
+		},
+		Response: resp.OK(),
+	}
+}
+
+func parseListQuery(c *echo.Context) (url.ListQuery, error) {
+	limit, err := echo.QueryParamOr[int](c, "limit", 0)
+	if err != nil {
+		return url.ListQuery{}, errors.New("invalid limit")
+	}
+	f, err := parseListFilter(c)
+	if err != nil {
+		return url.ListQuery{}, err
+	}
+
+	q := url.ListQuery{ListFilter: f, Limit: limit}
+	q.Sort, q.Desc = strings.CutPrefix(c.QueryParam("sort"), "-")
+
+	return q, nil
+}
+
+func parseListFilter(c *echo.Context) (url.ListFilter, error) {
+	f := url.ListFilter{
+		AliasPrefix: c.QueryParam("alias_prefix"),
+		Destination: c.QueryParam("destination"),
+		Tags:        c.QueryParams()["tag"],
+		State:       c.QueryParam("state"),
+	}
+
+	for param, dst := range map[string]*time.Time{
+		"created_from": &f.CreatedFrom,
+		"created_to":   &f.CreatedTo,
+	} {
+		v := c.QueryParam(param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return url.ListFilter{}, errors.New("invalid " + param + ": " + err.Error())
+		}
+		*dst = t
+	}
+
+	return f, nil
+}
+
+// parseExpiry resolves an absolute expires_at or a relative ttl into a point
+// in time. Supplying both is ambiguous and rejected; supplying neither yields
+// the zero time, i.e. no expiry.
+func parseExpiry(req schemes.UrlExpirySchema) (time.Time, error) {
+	if req.ExpiresAt != nil && req.Ttl != "" {
+		return time.Time{}, errors.New("expires_at and ttl are mutually exclusive")
+	}
+	if req.ExpiresAt != nil {
+		return *req.ExpiresAt, nil
+	}
+	if req.Ttl == "" {
+		return time.Time{}, nil
+	}
+
+	ttl, err := time.ParseDuration(req.Ttl)
+	if err != nil {
+		return time.Time{}, errors.New("invalid ttl: " + err.Error())
+	}
+	if ttl <= 0 {
+		return time.Time{}, errors.New("ttl must be positive")
+	}
+
+	return time.Now().Add(ttl), nil
+}
+
+func expiryOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/domain/workspace"
+	"awesomeProject/internal/http/schemes"
+	"awesomeProject/internal/service"
+	resp "awesomeProject/pkg/api/response"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+)
+
+type WorkspaceHandler struct {
+	serv service.WorkspaceService
+}
+
+func NewWorkspaceHandler(serv service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{serv: serv}
+}
+
+func (h *WorkspaceHandler) Create(c *echo.Context) error {
+	var req schemes.WorkspaceCreateSchema
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+	}
+
+	w, err := h.serv.Create(c.Request().Context(), req.Name, req.Domain)
+	if err != nil {
+		switch {
+		case errors.Is(err, workspace.ErrInvalid), errors.Is(err, url.ErrInvalidDomain):
+			return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+		case errors.Is(err, workspace.ErrNameTaken), errors.Is(err, url.ErrDomainTaken):
+			return c.JSON(http.StatusConflict, resp.Error(err.Error()))
+		case errors.Is(err, user.ErrForbidden):
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+		default:
+			return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+		}
+	}
+
+	return c.JSON(http.StatusCreated, toWorkspaceSchema(w))
+}
+
+func (h *WorkspaceHandler) List(c *echo.Context) error {
+	workspaces, err := h.serv.List(c.Request().Context())
+	if err != nil {
+		if errors.Is(err, user.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+		}
+		return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+	}
+
+	body := make([]schemes.WorkspaceGetSchema, len(workspaces))
+	for idx, w := range workspaces {
+		body[idx] = toWorkspaceSchema(w)
+	}
+
+	return c.JSON(http.StatusOK, body)
+}
+
+// AddMember serves POST /workspaces/:name/members.
+func (h *WorkspaceHandler) AddMember(c *echo.Context) error {
+	var req schemes.WorkspaceMemberSchema
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+	}
+
+	err := h.serv.AddMember(c.Request().Context(), c.Param("name"), req.User)
+	if err != nil {
+		switch {
+		case errors.Is(err, workspace.ErrNotFound):
+			return c.JSON(http.StatusNotFound, resp.Error("workspace not found"))
+		case errors.Is(err, user.ErrNotFound):
+			return c.JSON(http.StatusNotFound, resp.Error("user not found"))
+		case errors.Is(err, user.ErrForbidden):
+			return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+		default:
+			return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toWorkspaceSchema(w workspace.Workspace) schemes.WorkspaceGetSchema {
+	return schemes.WorkspaceGetSchema{
+		Id:        w.Id,
+		Name:      w.Name,
+		Domain:    w.Domain,
+		CreatedAt: w.CreatedAt,
+		Response:  resp.OK(),
+	}
+}
@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/service"
+	resp "awesomeProject/pkg/api/response"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+)
+
+const bearerPrefix = "Bearer "
+
+// BearerAuth authenticates requests by an `Authorization: Bearer <token>`
+// header and stores the resolved caller in the request context. The token is
+// an API key or a JWT depending on the authenticator.
+func BearerAuth(auth service.Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			token, ok := bearerToken(c.Request())
+			if !ok {
+				return unauthorized(c)
+			}
+
+			u, err := auth.Authenticate(c.Request().Context(), token)
+			if err != nil {
+				if errors.Is(err, user.ErrUnauthorized) {
+					return unauthorized(c)
+				}
+				return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+			}
+
+			ctx := user.WithUser(c.Request().Context(), u)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// RequireRole lets through callers authenticated by BearerAuth that hold
+// role; everyone else is turned away.
+func RequireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			u, ok := user.FromContext(c.Request().Context())
+			if !ok {
+				return unauthorized(c)
+			}
+			if !u.HasRole(role) {
+				return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+			}
+			return next(c)
+		}
+	}
+}
+
+func bearerToken(req *http.Request) (string, bool) {
+	header := req.Header.Get(echo.HeaderAuthorization)
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(bearerPrefix):]), true
+}
+
+func unauthorized(c *echo.Context) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+	return c.JSON(http.StatusUnauthorized, resp.Error("unauthorized"))
+}
@@ -2,6 +2,8 @@ package middlewares
 
 import (
 	"awesomeProject/pkg/logger"
+	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v5"
 )
@@ -14,3 +16,19 @@ func RequestContext(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+// Deadline gives the request d to upload its body and receive the response,
+// replacing the server wide read and write timeouts for routes that take
+// longer by design.
+func Deadline(d time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			rc := http.NewResponseController(c.Response())
+			deadline := time.Now().Add(d)
+			// writers without deadlines have no timeouts to replace
+			_ = rc.SetReadDeadline(deadline)
+			_ = rc.SetWriteDeadline(deadline)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/domain/workspace"
+	"awesomeProject/internal/service"
+	resp "awesomeProject/pkg/api/response"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+)
+
+const HeaderWorkspace = "X-Workspace"
+
+// Workspace lets authenticated callers act in a workspace named by the
+// X-Workspace header. Requests without the header act on personal links.
+func Workspace(workspaces service.WorkspaceService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			name := c.Request().Header.Get(HeaderWorkspace)
+			if name == "" {
+				return next(c)
+			}
+
+			w, err := workspaces.Enter(c.Request().Context(), name)
+			if err != nil {
+				switch {
+				case errors.Is(err, workspace.ErrNotFound):
+					return c.JSON(http.StatusNotFound, resp.Error("workspace not found"))
+				case errors.Is(err, user.ErrForbidden):
+					return c.JSON(http.StatusForbidden, resp.Error("forbidden"))
+				default:
+					return c.JSON(http.StatusInternalServerError, resp.Error(err.Error()))
+				}
+			}
+
+			ctx := workspace.WithWorkspace(c.Request().Context(), w)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package schemes
+
+import (
+	resp "awesomeProject/pkg/api/response"
+	"time"
+)
+
+type DomainCreateSchema struct {
+	Host      string `json:"host"`
+	Workspace string `json:"workspace,omitempty"`
+}
+
+type DomainGetSchema struct {
+	Id          int       `json:"id"`
+	Host        string    `json:"host"`
+	WorkspaceId int       `json:"workspace_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	resp.Response
+}
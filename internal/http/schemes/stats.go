@@ -0,0 +1,23 @@
+package schemes
+
+import (
+	resp "awesomeProject/pkg/api/response"
+	"time"
+)
+
+type StatsBucketSchema struct {
+	Start          time.Time `json:"start"`
+	Clicks         int64     `json:"clicks"`
+	UniqueVisitors int64     `json:"unique_visitors"`
+}
+
+type StatsGetSchema struct {
+	Alias          string              `json:"alias"`
+	From           time.Time           `json:"from"`
+	To             time.Time           `json:"to"`
+	Interval       string              `json:"interval"`
+	TotalClicks    int64               `json:"total_clicks"`
+	UniqueVisitors int64               `json:"unique_visitors"`
+	Series         []StatsBucketSchema `json:"series"`
+	resp.Response
+}
@@ -2,6 +2,7 @@ package schemes
 
 import (
 	resp "awesomeProject/pkg/api/response"
+	"time"
 )
 
 type UrlBaseSchema struct {
@@ -9,18 +10,105 @@ type UrlBaseSchema struct {
 	Alias       string `json:"alias"`
 }
 
+type UrlExpirySchema struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Ttl       string     `json:"ttl,omitempty"`
+}
+
+type UrlDetailsSchema struct {
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+}
+
+// UrlDetailsPatchSchema replaces the fields present in the request; an empty
+// list or object clears tags or metadata.
+type UrlDetailsPatchSchema struct {
+	Title       *string        `json:"title,omitempty"`
+	Description *string        `json:"description,omitempty"`
+	Tags        *[]string      `json:"tags,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+}
+
 type UrlGetSchema struct {
 	Id int `json:"id"`
 	UrlBaseSchema
+	Domain    string     `json:"domain,omitempty"`
+	ShortUrl  string     `json:"short_url"`
+	CreatedAt time.Time  `json:"created_at"`
+	Clicks    int        `json:"clicks"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	UrlDetailsSchema
+	resp.Response
+}
+
+type UrlListSchema struct {
+	Urls       []UrlGetSchema `json:"urls"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	resp.Response
+}
+
+type UrlSearchHitSchema struct {
+	UrlGetSchema
+	Rank float64 `json:"rank"`
+	// Highlights holds the matching fields as HTML with <mark>ed matches.
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+type UrlSearchSchema struct {
+	Results []UrlSearchHitSchema `json:"results"`
 	resp.Response
 }
 
 type UrlCreateSchema struct {
 	UrlBaseSchema
+	// Domain is a registered host to serve the link on, empty for the
+	// workspace's default.
+	Domain string `json:"domain,omitempty"`
+	UrlExpirySchema
+	UrlDetailsSchema
 }
 
 type UrlUpdateSchema struct {
 	Id     int    `json:"id"`
 	NewUrl string `json:"new_url"`
 	Alias  string `json:"alias"`
+	UrlExpirySchema
+	UrlDetailsPatchSchema
+}
+
+type UrlDeleteSchema struct {
+	Id int `json:"id"`
+}
+
+// UrlBulkItemSchema reports one item of a bulk request by its position.
+type UrlBulkItemSchema struct {
+	Index int           `json:"index"`
+	Id    int           `json:"id,omitempty"`
+	Url   *UrlGetSchema `json:"url,omitempty"`
+	resp.Response
+}
+
+type UrlBulkSchema struct {
+	Results   []UrlBulkItemSchema `json:"results"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	resp.Response
+}
+
+// UrlImportRowSchema reports one data row of an imported CSV file by its
+// line number.
+type UrlImportRowSchema struct {
+	Line int           `json:"line"`
+	Url  *UrlGetSchema `json:"url,omitempty"`
+	resp.Response
+}
+
+type UrlImportSchema struct {
+	DryRun    bool                 `json:"dry_run"`
+	Results   []UrlImportRowSchema `json:"results"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	resp.Response
 }
@@ -0,0 +1,23 @@
+package schemes
+
+import (
+	resp "awesomeProject/pkg/api/response"
+	"time"
+)
+
+type WorkspaceCreateSchema struct {
+	Name   string `json:"name"`
+	Domain string `json:"domain,omitempty"`
+}
+
+type WorkspaceGetSchema struct {
+	Id        int       `json:"id"`
+	Name      string    `json:"name"`
+	Domain    string    `json:"domain,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	resp.Response
+}
+
+type WorkspaceMemberSchema struct {
+	User string `json:"user"`
+}
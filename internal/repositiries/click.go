@@ -0,0 +1,97 @@
+package repositiries
+
+import (
+	"awesomeProject/internal/domain/click"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ClickRepository interface {
+	SaveBatch(ctx context.Context, events []click.Event) error
+	Stats(ctx context.Context, urlId int, from, to time.Time, interval string) (click.Stats, error)
+}
+
+type clickRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewClickRepository(pool *pgxpool.Pool) ClickRepository {
+	return &clickRepository{pool: pool}
+}
+
+func (r *clickRepository) SaveBatch(ctx context.Context, events []click.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	_, err := r.pool.CopyFrom(
+		ctx,
+		pgx.Identifier{"click_events"},
+		[]string{"url_id", "clicked_at", "referrer", "user_agent_family", "ip_prefix", "request_id"},
+		pgx.CopyFromSlice(len(events), func(i int) ([]any, error) {
+			e := events[i]
+			return []any{e.UrlId, e.ClickedAt, e.Referrer, e.UserAgentFamily, e.IpPrefix, e.RequestId}, nil
+		}),
+	)
+
+	return err
+}
+
+// visitorKey identifies a visitor for unique counts; null-safe concatenation
+// keeps empty left-joined buckets from counting as a visitor.
+const visitorKey = "e.ip_prefix || '|' || e.user_agent_family"
+
+func (r *clickRepository) Stats(
+	ctx context.Context,
+	urlId int,
+	from, to time.Time,
+	interval string,
+) (click.Stats, error) {
+	stats := click.Stats{From: from, To: to, Interval: interval}
+
+	totalsSql := `
+		select count(*), count(distinct ` + visitorKey + `)
+		from click_events e
+		where e.url_id = $1 and e.clicked_at >= $2 and e.clicked_at < $3`
+	err := r.pool.QueryRow(ctx, totalsSql, urlId, from, to).
+		Scan(&stats.TotalClicks, &stats.UniqueVisitors)
+	if err != nil {
+		return click.Stats{}, err
+	}
+
+	seriesSql := `
+		select b.start, count(e.id), count(distinct ` + visitorKey + `)
+		from generate_series(
+			date_trunc($4, $2::timestamptz),
+			$3::timestamptz - interval '1 microsecond',
+			('1 ' || $4)::interval
+		) as b(start)
+		left join click_events e
+			on e.url_id = $1
+			and e.clicked_at >= greatest(b.start, $2)
+			and e.clicked_at < least(b.start + ('1 ' || $4)::interval, $3)
+		group by b.start
+		order by b.start`
+	rows, err := r.pool.Query(ctx, seriesSql, urlId, from, to, interval)
+	if err != nil {
+		return click.Stats{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b click.Bucket
+		if err := rows.Scan(&b.Start, &b.Clicks, &b.UniqueVisitors); err != nil {
+			return click.Stats{}, err
+		}
+		stats.Series = append(stats.Series, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return click.Stats{}, err
+	}
+
+	return stats, nil
+}
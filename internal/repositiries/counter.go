@@ -0,0 +1,35 @@
+package repositiries
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CounterRepository interface {
+	// Reserve atomically claims size consecutive ids from the named counter
+	// and returns the first one. The counter is created on first use.
+	Reserve(ctx context.Context, name string, size int64) (int64, error)
+}
+
+type counterRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCounterRepository(pool *pgxpool.Pool) CounterRepository {
+	return &counterRepository{pool: pool}
+}
+
+func (r *counterRepository) Reserve(ctx context.Context, name string, size int64) (int64, error) {
+	const sql = `
+		insert into alias_counter (name, next_id) values ($1, 1 + $2)
+		on conflict (name) do update set next_id = alias_counter.next_id + $2
+		returning next_id - $2`
+
+	var start int64
+	if err := r.pool.QueryRow(ctx, sql, name, size).Scan(&start); err != nil {
+		return 0, err
+	}
+
+	return start, nil
+}
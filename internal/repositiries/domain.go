@@ -0,0 +1,118 @@
+package repositiries
+
+import (
+	"awesomeProject/internal/domain/url"
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var domainColumns = []string{"id", "host", "workspace_id", "created_at"}
+
+type DomainRepository interface {
+	Create(ctx context.Context, host string, workspaceId int) (url.Domain, error)
+	GetByHost(ctx context.Context, host string) (url.Domain, error)
+	List(ctx context.Context) ([]url.Domain, error)
+}
+
+type domainRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDomainRepository(pool *pgxpool.Pool) DomainRepository {
+	return &domainRepository{pool: pool}
+}
+
+func (r *domainRepository) Create(ctx context.Context, host string, workspaceId int) (url.Domain, error) {
+	return createDomain(ctx, r.pool, host, workspaceId)
+}
+
+func (r *domainRepository) GetByHost(ctx context.Context, host string) (url.Domain, error) {
+	sql, args, err := sq.
+		Select(domainColumns...).From("domains").
+		Where(sq.Eq{"host": host}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return url.Domain{}, err
+	}
+
+	d, err := scanDomain(r.pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return url.Domain{}, url.ErrUnknownDomain
+		}
+		return url.Domain{}, err
+	}
+
+	return d, nil
+}
+
+func (r *domainRepository) List(ctx context.Context) ([]url.Domain, error) {
+	sql, args, err := sq.
+		Select(domainColumns...).From("domains").OrderBy("host").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []url.Domain
+	for rows.Next() {
+		d, err := scanDomain(rows)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return domains, nil
+}
+
+// querier is implemented by both the pool and transactions.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func createDomain(ctx context.Context, q querier, host string, workspaceId int) (url.Domain, error) {
+	sql, args, err := sq.
+		Insert("domains").Columns("host", "workspace_id").Values(host, nullInt(workspaceId)).
+		Suffix("returning id, host, workspace_id, created_at").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return url.Domain{}, err
+	}
+
+	d, err := scanDomain(q.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return url.Domain{}, url.ErrDomainTaken
+		}
+		return url.Domain{}, err
+	}
+
+	return d, nil
+}
+
+func scanDomain(row pgx.Row) (url.Domain, error) {
+	var d url.Domain
+	var workspaceId *int
+	if err := row.Scan(&d.Id, &d.Host, &workspaceId, &d.CreatedAt); err != nil {
+		return url.Domain{}, err
+	}
+	if workspaceId != nil {
+		d.WorkspaceId = *workspaceId
+	}
+
+	return d, nil
+}
@@ -0,0 +1,29 @@
+package repositiries
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SequenceRepository interface {
+	Next(ctx context.Context) (int64, error)
+}
+
+type sequenceRepository struct {
+	pool *pgxpool.Pool
+	name string
+}
+
+func NewSequenceRepository(pool *pgxpool.Pool, name string) SequenceRepository {
+	return &sequenceRepository{pool: pool, name: name}
+}
+
+func (r *sequenceRepository) Next(ctx context.Context) (int64, error) {
+	var id int64
+	if err := r.pool.QueryRow(ctx, "select nextval($1::regclass)", r.name).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
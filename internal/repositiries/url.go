@@ -3,17 +3,81 @@ package repositiries
 import (
 	"awesomeProject/internal/domain/url"
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// UrlRepository methods taking a url.Scope restrict themselves to the links in
+// that scope.
 type UrlRepository interface {
-	Save(ctx context.Context, urlToSave, alias string) error
-	List(ctx context.Context) ([]url.Url, error)
+	// Save inserts u's destination, alias, expiry, owner, workspace and
+	// domain and returns the stored row.
+	Save(ctx context.Context, u url.Url, urlHash string) (url.Url, error)
+	// List returns up to q.Limit links matching q in q's sort order,
+	// starting after the after cursor unless it is nil.
+	List(ctx context.Context, scope url.Scope, q url.ListQuery, after *url.Cursor) ([]url.Url, error)
+	// Search returns up to limit links whose search vector matches the
+	// tsquery, best ranked first.
+	Search(ctx context.Context, scope url.Scope, tsquery string, limit int) ([]url.SearchHit, error)
 	Get(ctx context.Context, id int) (url.Url, error)
-	Update(ctx context.Context, id int, newUrl, alias string) error
-	Delete(ctx context.Context, id int) error
+	// GetByAlias looks alias up among the links served on host; hosts that
+	// are not a registered domain serve the default host's links.
+	GetByAlias(ctx context.Context, host, alias string) (url.Url, error)
+	// GetByHash finds the link with urlHash saved by the same owner, in the
+	// same workspace and on the same domain as u.
+	GetByHash(ctx context.Context, u url.Url, urlHash string) (url.Url, error)
+	Update(
+		ctx context.Context,
+		scope url.Scope,
+		id int,
+		newUrl, alias string,
+		expiresAt time.Time,
+		details url.DetailsPatch,
+	) error
+	Delete(ctx context.Context, scope url.Scope, id int) error
+	// SaveMany inserts links, whose aliases must be distinct per domain, in
+	// one transaction. Links whose alias is taken are skipped and get
+	// url.ErrAliasTaken; the rest get their stored row.
+	SaveMany(ctx context.Context, links []url.Url) ([]url.ItemResult, error)
+	// UpdateMany applies changes in one transaction and returns an error per
+	// change: url.ErrNotFound, url.ErrAliasTaken or nil once applied.
+	UpdateMany(ctx context.Context, scope url.Scope, changes []url.Change) ([]error, error)
+	// DeleteMany deletes the links with ids in one statement and returns
+	// url.ErrNotFound for the ids that were not deleted.
+	DeleteMany(ctx context.Context, scope url.Scope, ids []int) ([]error, error)
+	// TakenAliases reports for each link whether its alias is already used
+	// on its domain.
+	TakenAliases(ctx context.Context, links []url.Url) ([]bool, error)
+	// Export calls fn with every link matching f in id order, reading rows
+	// as fn consumes them. An error from fn stops the export and is
+	// returned.
+	Export(ctx context.Context, scope url.Scope, f url.ListFilter, fn func(url.Url) error) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+	ArchiveExpired(ctx context.Context, now time.Time) (int64, error)
+	IncrementClicks(ctx context.Context, clicks map[int]int64) error
+}
+
+var urlColumns = []string{
+	"url.id", "url.original_url", "url.alias", "url.created_at", "url.expires_at", "url.clicks",
+	"url.owner_id", "url.workspace_id", "url.domain_id",
+	"url.tags", "url.title", "url.description", "url.metadata",
+	// kept last, see Save
+	"domains.host",
+}
+
+// selectUrls selects urlColumns, joining in the host of each link's domain.
+func selectUrls() sq.SelectBuilder {
+	return sq.Select(urlColumns...).From("url").
+		LeftJoin("domains on domains.id = url.domain_id").
+		PlaceholderFormat(sq.Dollar)
 }
 
 type urlRepository struct {
@@ -24,29 +88,66 @@ func NewUrlRepository(pool *pgxpool.Pool) UrlRepository {
 	return &urlRepository{pool: pool}
 }
 
-func (r *urlRepository) Save(ctx context.Context, urlToSave, alias string) error {
+func (r *urlRepository) Save(ctx context.Context, u url.Url, urlHash string) (url.Url, error) {
 	sql, args, err := sq.
-		Insert("url").Columns("original_url", "alias").
-		Values(urlToSave, alias).PlaceholderFormat(sq.Dollar).ToSql()
+		Insert("url").
+		Columns(
+			"original_url", "alias", "url_hash", "expires_at", "owner_id", "workspace_id", "domain_id",
+			"tags", "title", "description", "metadata",
+		).
+		Values(
+			u.OriginalUrl, u.Alias, nullString(urlHash), nullTime(u.ExpiresAt),
+			nullInt(u.OwnerId), nullInt(u.WorkspaceId), nullInt(u.DomainId),
+			emptyTags(u.Tags), nullString(u.Title), nullString(u.Description), emptyMetadata(u.Metadata),
+		).
+		// the domain is known to the caller, no need to join it back in
+		Suffix("returning " + strings.Join(urlColumns[:len(urlColumns)-1], ", ") + ", null").
+		PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
-		return err
+		return url.Url{}, err
 	}
 
-	_, err = r.pool.Exec(ctx, sql, args...)
+	saved, err := scanUrl(r.pool.QueryRow(ctx, sql, args...))
 	if err != nil {
+		if isConstraintViolation(err, urlHashConstraint) {
+			return url.Url{}, url.ErrDuplicateUrl
+		}
 		if isUniqueViolation(err) {
-			return url.ErrAliasTaken
+			return url.Url{}, url.ErrAliasTaken
 		}
-		return err
+		return url.Url{}, err
 	}
+	saved.Domain = u.Domain
 
-	return nil
+	return saved, nil
 }
 
-func (r *urlRepository) List(ctx context.Context) ([]url.Url, error) {
-	sql, args, err := sq.
-		Select("id", "original_url", "alias").From("url").
-		OrderBy("created_at").PlaceholderFormat(sq.Dollar).ToSql()
+func (r *urlRepository) List(
+	ctx context.Context,
+	scope url.Scope,
+	q url.ListQuery,
+	after *url.Cursor,
+) ([]url.Url, error) {
+	builder := filtered(selectUrls().Where(scoped(scope, sq.Eq{})), q.ListFilter)
+
+	column, op, dir := "url.created_at", ">", "asc"
+	if q.Sort == url.SortClicks {
+		column = "url.clicks"
+	}
+	if q.Desc {
+		op, dir = "<", "desc"
+	}
+	if after != nil {
+		var value any = after.CreatedAt
+		if q.Sort == url.SortClicks {
+			value = after.Clicks
+		}
+		builder = builder.Where(fmt.Sprintf("(%s, url.id) %s (?, ?)", column, op), value, after.Id)
+	}
+
+	sql, args, err := builder.
+		OrderBy(column+" "+dir, "url.id "+dir).
+		Limit(uint64(q.Limit)).ToSql()
 	if err != nil {
 		return nil, err
 	}
@@ -59,8 +160,8 @@ func (r *urlRepository) List(ctx context.Context) ([]url.Url, error) {
 
 	var urls []url.Url
 	for rows.Next() {
-		var u url.Url
-		if err := rows.Scan(&u.Id, &u.OriginalUrl, &u.Alias); err != nil {
+		u, err := scanUrl(rows)
+		if err != nil {
 			return nil, err
 		}
 		urls = append(urls, u)
@@ -73,53 +174,380 @@ func (r *urlRepository) List(ctx context.Context) ([]url.Url, error) {
 	return urls, nil
 }
 
+func (r *urlRepository) Search(
+	ctx context.Context,
+	scope url.Scope,
+	tsquery string,
+	limit int,
+) ([]url.SearchHit, error) {
+	sql, args, err := selectUrls().
+		Column("ts_rank(url.search, to_tsquery('simple', ?)) as rank", tsquery).
+		Where(scoped(scope, sq.Eq{})).
+		Where("url.search @@ to_tsquery('simple', ?)", tsquery).
+		OrderBy("rank desc", "url.id").
+		Limit(uint64(limit)).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []url.SearchHit
+	for rows.Next() {
+		var hit url.SearchHit
+		hit.Url, err = scanUrl(rows, &hit.Rank)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
+
+func (r *urlRepository) Export(
+	ctx context.Context,
+	scope url.Scope,
+	f url.ListFilter,
+	fn func(url.Url) error,
+) error {
+	sql, args, err := filtered(selectUrls().Where(scoped(scope, sq.Eq{})), f).
+		OrderBy("url.id").ToSql()
+	if err != nil {
+		return err
+	}
+
+	// pgx reads the result off the connection as rows are consumed, so the
+	// export never holds more than a row in memory
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		u, err := scanUrl(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 func (r *urlRepository) Get(ctx context.Context, id int) (url.Url, error) {
-	sql, args, err := sq.
-		Select("id", "original_url", "alias").From("url").
-		Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+	sql, args, err := selectUrls().Where(sq.Eq{"url.id": id}).ToSql()
 	if err != nil {
 		return url.Url{}, err
 	}
 
-	row := r.pool.QueryRow(ctx, sql, args...)
-	var u url.Url
-	if err := row.Scan(&u.Id, &u.OriginalUrl, &u.Alias); err != nil {
+	u, err := scanUrl(r.pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return url.Url{}, url.ErrNotFound
+		}
 		return url.Url{}, err
 	}
 
 	return u, nil
 }
 
-func (r *urlRepository) Update(ctx context.Context, id int, newUrl, alias string) error {
-	builder := sq.Update("url").Set("original_url", newUrl)
-	if alias != "" {
-		builder = builder.Set("alias", alias)
+func (r *urlRepository) GetByAlias(ctx context.Context, host, alias string) (url.Url, error) {
+	sql, args, err := selectUrls().
+		Where(sq.Eq{"url.alias": alias}).
+		Where("coalesce(url.domain_id, 0) = coalesce((select id from domains where host = ?), 0)", host).
+		ToSql()
+	if err != nil {
+		return url.Url{}, err
 	}
-	sql, args, err := builder.Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+
+	u, err := scanUrl(r.pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return url.Url{}, url.ErrNotFound
+		}
+		return url.Url{}, err
+	}
+
+	return u, nil
+}
+
+func (r *urlRepository) GetByHash(ctx context.Context, u url.Url, urlHash string) (url.Url, error) {
+	sql, args, err := selectUrls().
+		Where(sq.Eq{
+			"url.url_hash":     urlHash,
+			"url.owner_id":     nullInt(u.OwnerId),
+			"url.workspace_id": nullInt(u.WorkspaceId),
+			"url.domain_id":    nullInt(u.DomainId),
+			// hashes set before expiries cleared them may still sit on
+			// expiring links, which are never handed out as duplicates
+			"url.expires_at": nil,
+		}).
+		ToSql()
+	if err != nil {
+		return url.Url{}, err
+	}
+
+	existing, err := scanUrl(r.pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return url.Url{}, url.ErrNotFound
+		}
+		return url.Url{}, err
+	}
+
+	return existing, nil
+}
+
+func (r *urlRepository) Update(
+	ctx context.Context,
+	scope url.Scope,
+	id int,
+	newUrl, alias string,
+	expiresAt time.Time,
+	details url.DetailsPatch,
+) error {
+	sql, args, err := updateStatement(scope, url.Change{
+		Id:        id,
+		NewUrl:    newUrl,
+		Alias:     alias,
+		ExpiresAt: expiresAt,
+		Details:   details,
+	})
 	if err != nil {
 		return err
 	}
 
-	_, err = r.pool.Exec(ctx, sql, args...)
+	tag, err := r.pool.Exec(ctx, sql, args...)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return url.ErrAliasTaken
+		}
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		return url.ErrNotFound
+	}
 
 	return nil
 }
 
-func (r *urlRepository) Delete(ctx context.Context, id int) error {
+// updateStatement builds the update applying c to the link in scope.
+func updateStatement(scope url.Scope, c url.Change) (string, []any, error) {
+	// the dedupe hash describes the old destination, so drop it if that
+	// changes; links with an expiry or details are never deduplicated at all
+	var hash any = sq.Expr("case when original_url = ? then url_hash end", c.NewUrl)
+	if !c.ExpiresAt.IsZero() || !c.Details.IsZero() {
+		hash = nil
+	}
+	builder := sq.Update("url").
+		Set("url_hash", hash).
+		Set("original_url", c.NewUrl)
+	if c.Alias != "" {
+		builder = builder.Set("alias", c.Alias)
+	}
+	if !c.ExpiresAt.IsZero() {
+		builder = builder.Set("expires_at", c.ExpiresAt)
+	}
+	if c.Details.Title != nil {
+		builder = builder.Set("title", nullString(*c.Details.Title))
+	}
+	if c.Details.Description != nil {
+		builder = builder.Set("description", nullString(*c.Details.Description))
+	}
+	if c.Details.Tags != nil {
+		builder = builder.Set("tags", emptyTags(*c.Details.Tags))
+	}
+	if c.Details.Metadata != nil {
+		builder = builder.Set("metadata", c.Details.Metadata)
+	}
+
+	return builder.
+		Where(scoped(scope, sq.Eq{"url.id": c.Id})).PlaceholderFormat(sq.Dollar).ToSql()
+}
+
+func (r *urlRepository) Delete(ctx context.Context, scope url.Scope, id int) error {
 	sql, args, err := sq.
-		Delete("url").Where(sq.Eq{"id": id}).
+		Delete("url").Where(scoped(scope, sq.Eq{"url.id": id})).
+		Suffix("returning url.id").
 		PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return err
 	}
 
-	_, err = r.pool.Exec(ctx, sql, args...)
+	var n int
+	err = r.pool.QueryRow(ctx, withClickEvents(sql, "select count(*) from deleted"), args...).Scan(&n)
 	if err != nil {
 		return err
 	}
+	if n == 0 {
+		return url.ErrNotFound
+	}
 
 	return nil
 }
+
+func (r *urlRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	sql, args, err := sq.
+		Delete("url").Where(sq.LtOrEq{"expires_at": now}).
+		Suffix("returning url.id").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	err = r.pool.QueryRow(ctx, withClickEvents(sql, "select count(*) from deleted"), args...).Scan(&n)
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// withClickEvents extends deleteSql, a delete from url returning the ids of
+// the removed links, to remove their click events too. Archived links keep
+// theirs. The statement yields the result of query over the deleted ids.
+func withClickEvents(deleteSql, query string) string {
+	return `with deleted (id) as (` + deleteSql + `),
+		events as (delete from click_events where url_id in (select id from deleted))
+		` + query
+}
+
+func (r *urlRepository) ArchiveExpired(ctx context.Context, now time.Time) (int64, error) {
+	const sql = `
+		with expired as (
+			delete from url where expires_at <= $1
+			returning id, original_url, alias, created_at, expires_at, clicks, owner_id, workspace_id, domain_id,
+				tags, title, description, metadata
+		)
+		insert into url_archive (
+			id, original_url, alias, created_at, expires_at, clicks, owner_id, workspace_id, domain_id,
+			tags, title, description, metadata
+		)
+		select id, original_url, alias, created_at, expires_at, clicks, owner_id, workspace_id, domain_id,
+			tags, title, description, metadata
+		from expired`
+
+	tag, err := r.pool.Exec(ctx, sql, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r *urlRepository) IncrementClicks(ctx context.Context, clicks map[int]int64) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	// updating in id order keeps concurrent flushes of overlapping links from
+	// deadlocking on each other's row locks
+	ids := slices.Sorted(maps.Keys(clicks))
+	batch := &pgx.Batch{}
+	for _, id := range ids {
+		sql, args, err := sq.
+			Update("url").Set("clicks", sq.Expr("clicks + ?", clicks[id])).
+			Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+		if err != nil {
+			return err
+		}
+		batch.Queue(sql, args...)
+	}
+
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+// scanUrl scans urlColumns followed by the destinations in extra.
+func scanUrl(row pgx.Row, extra ...any) (url.Url, error) {
+	var u url.Url
+	var expiresAt *time.Time
+	var ownerId, workspaceId, domainId *int
+	var title, description, domain *string
+	dest := []any{
+		&u.Id, &u.OriginalUrl, &u.Alias, &u.CreatedAt, &expiresAt, &u.Clicks,
+		&ownerId, &workspaceId, &domainId,
+		&u.Tags, &title, &description, &u.Metadata,
+		&domain,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return url.Url{}, err
+	}
+	if expiresAt != nil {
+		u.ExpiresAt = *expiresAt
+	}
+	if ownerId != nil {
+		u.OwnerId = *ownerId
+	}
+	if workspaceId != nil {
+		u.WorkspaceId = *workspaceId
+	}
+	if domainId != nil {
+		u.DomainId = *domainId
+	}
+	if title != nil {
+		u.Title = *title
+	}
+	if description != nil {
+		u.Description = *description
+	}
+	if domain != nil {
+		u.Domain = *domain
+	}
+
+	return u, nil
+}
+
+// filtered adds the conditions of f to builder.
+func filtered(builder sq.SelectBuilder, f url.ListFilter) sq.SelectBuilder {
+	if f.AliasPrefix != "" {
+		builder = builder.Where(sq.Like{"url.alias": escapeLike(f.AliasPrefix) + "%"})
+	}
+	if f.Destination != "" {
+		builder = builder.Where(sq.ILike{"url.original_url": "%" + escapeLike(f.Destination) + "%"})
+	}
+	if !f.CreatedFrom.IsZero() {
+		builder = builder.Where(sq.GtOrEq{"url.created_at": f.CreatedFrom})
+	}
+	if !f.CreatedTo.IsZero() {
+		builder = builder.Where(sq.Lt{"url.created_at": f.CreatedTo})
+	}
+	if len(f.Tags) > 0 {
+		builder = builder.Where("url.tags @> ?", f.Tags)
+	}
+	switch f.State {
+	case url.StateActive:
+		builder = builder.Where(sq.Or{sq.Eq{"url.expires_at": nil}, sq.Gt{"url.expires_at": f.Now}})
+	case url.StateExpired:
+		builder = builder.Where(sq.LtOrEq{"url.expires_at": f.Now})
+	}
+	return builder
+}
+
+// scoped adds the restrictions of scope to eq, see url.Scope.
+func scoped(scope url.Scope, eq sq.Eq) sq.Eq {
+	switch {
+	case scope.WorkspaceId != 0:
+		eq["url.workspace_id"] = scope.WorkspaceId
+		if scope.OwnerId != 0 {
+			eq["url.owner_id"] = scope.OwnerId
+		}
+	case scope.OwnerId != 0:
+		eq["url.owner_id"] = scope.OwnerId
+		eq["url.workspace_id"] = nil
+	}
+	return eq
+}
@@ -0,0 +1,290 @@
+package repositiries
+
+import (
+	"awesomeProject/internal/domain/url"
+	"context"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// aliasKey identifies an alias on its domain, 0 being the default host.
+type aliasKey struct {
+	domainId int
+	alias    string
+}
+
+var stagedColumns = []string{
+	"original_url", "alias", "expires_at", "owner_id", "workspace_id", "domain_id",
+	"tags", "title", "description", "metadata",
+}
+
+// SaveMany copies the links into a staging table and moves them into url
+// with a single insert, letting the alias index skip the taken ones. The
+// links' aliases must be distinct per domain.
+func (r *urlRepository) SaveMany(ctx context.Context, links []url.Url) ([]url.ItemResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		create temp table url_staging (
+			original_url text not null,
+			alias text not null,
+			expires_at timestamptz,
+			owner_id integer,
+			workspace_id integer,
+			domain_id integer,
+			tags text[] not null,
+			title text,
+			description text,
+			metadata jsonb not null
+		) on commit drop`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"url_staging"},
+		stagedColumns,
+		pgx.CopyFromSlice(len(links), func(i int) ([]any, error) {
+			u := links[i]
+			return []any{
+				u.OriginalUrl, u.Alias, nullTime(u.ExpiresAt),
+				nullInt(u.OwnerId), nullInt(u.WorkspaceId), nullInt(u.DomainId),
+				emptyTags(u.Tags), nullString(u.Title), nullString(u.Description), emptyMetadata(u.Metadata),
+			}, nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := strings.Join(stagedColumns, ", ")
+	rows, err := tx.Query(ctx,
+		"insert into url ("+columns+") select "+columns+" from url_staging "+
+			"on conflict do nothing "+
+			"returning "+strings.Join(urlColumns[:len(urlColumns)-1], ", ")+", null",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	saved := make(map[aliasKey]url.Url, len(links))
+	for rows.Next() {
+		u, err := scanUrl(rows)
+		if err != nil {
+			return nil, err
+		}
+		saved[aliasKey{u.DomainId, u.Alias}] = u
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	results := make([]url.ItemResult, len(links))
+	for i, link := range links {
+		u, ok := saved[aliasKey{link.DomainId, link.Alias}]
+		if !ok {
+			results[i].Err = url.ErrAliasTaken
+			continue
+		}
+		u.Domain = link.Domain
+		results[i].Url = u
+	}
+
+	return results, nil
+}
+
+// UpdateMany locks the changed links, checks the new aliases against the
+// stored ones and the other changes, and then sends the updates that can
+// succeed as one batch.
+func (r *urlRepository) UpdateMany(ctx context.Context, scope url.Scope, changes []url.Change) ([]error, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	ids := make([]int, len(changes))
+	var aliases []string
+	for i, c := range changes {
+		ids[i] = c.Id
+		if c.Alias != "" {
+			aliases = append(aliases, c.Alias)
+		}
+	}
+
+	// current alias key of every link in scope that is being changed
+	sql, args, err := sq.
+		Select("url.id", "coalesce(url.domain_id, 0)", "url.alias").From("url").
+		Where(scoped(scope, sq.Eq{})).Where("url.id = any(?)", ids).
+		Suffix("for update").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+	current, err := queryAliasKeys(ctx, tx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// owners of the requested aliases on any domain, outside the scope too
+	taken, err := queryAliasKeys(ctx, tx,
+		"select id, coalesce(domain_id, 0), alias from url where alias = any($1)", aliases)
+	if err != nil {
+		return nil, err
+	}
+	owner := make(map[aliasKey]int, len(taken))
+	for id, key := range taken {
+		owner[key] = id
+	}
+
+	errs := make([]error, len(changes))
+	batch := &pgx.Batch{}
+	var queued []int
+	for i, c := range changes {
+		key, ok := current[c.Id]
+		if !ok {
+			errs[i] = url.ErrNotFound
+			continue
+		}
+		if c.Alias != "" && c.Alias != key.alias {
+			next := aliasKey{key.domainId, c.Alias}
+			if id, ok := owner[next]; ok && id != c.Id {
+				errs[i] = url.ErrAliasTaken
+				continue
+			}
+			// later changes in the batch can neither take this alias nor
+			// the one given up, which is only free once the batch has run
+			owner[next] = c.Id
+		}
+
+		sql, args, err := updateStatement(scope, c)
+		if err != nil {
+			return nil, err
+		}
+		batch.Queue(sql, args...)
+		queued = append(queued, i)
+	}
+
+	if len(queued) > 0 {
+		results := tx.SendBatch(ctx, batch)
+		for range queued {
+			if _, err := results.Exec(); err != nil {
+				results.Close()
+				if isUniqueViolation(err) {
+					// an alias was claimed concurrently, nothing was applied
+					return nil, url.ErrAliasTaken
+				}
+				return nil, err
+			}
+		}
+		if err := results.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	return errs, tx.Commit(ctx)
+}
+
+// queryAliasKeys runs a query selecting id, domain id and alias and maps each
+// id to its alias key.
+func queryAliasKeys(
+	ctx context.Context,
+	tx pgx.Tx,
+	sql string,
+	args ...any,
+) (map[int]aliasKey, error) {
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[int]aliasKey)
+	for rows.Next() {
+		var id int
+		var key aliasKey
+		if err := rows.Scan(&id, &key.domainId, &key.alias); err != nil {
+			return nil, err
+		}
+		keys[id] = key
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *urlRepository) DeleteMany(ctx context.Context, scope url.Scope, ids []int) ([]error, error) {
+	sql, args, err := sq.
+		Delete("url").Where(scoped(scope, sq.Eq{})).Where("url.id = any(?)", ids).
+		Suffix("returning url.id").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, withClickEvents(sql, "select id from deleted"), args...)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+
+	gone := make(map[int]bool, len(deleted))
+	for _, id := range deleted {
+		gone[id] = true
+	}
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		if !gone[id] {
+			errs[i] = url.ErrNotFound
+		}
+	}
+
+	return errs, nil
+}
+
+func (r *urlRepository) TakenAliases(ctx context.Context, links []url.Url) ([]bool, error) {
+	aliases := make([]string, len(links))
+	for i, link := range links {
+		aliases[i] = link.Alias
+	}
+
+	rows, err := r.pool.Query(ctx,
+		"select coalesce(domain_id, 0), alias from url where alias = any($1)", aliases)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	used := make(map[aliasKey]bool)
+	for rows.Next() {
+		var key aliasKey
+		if err := rows.Scan(&key.domainId, &key.alias); err != nil {
+			return nil, err
+		}
+		used[key] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	taken := make([]bool, len(links))
+	for i, link := range links {
+		taken[i] = used[aliasKey{link.DomainId, link.Alias}]
+	}
+
+	return taken, nil
+}
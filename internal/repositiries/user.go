@@ -0,0 +1,169 @@
+package repositiries
+
+import (
+	"awesomeProject/internal/domain/user"
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, name, role string) (user.User, error)
+	GetByName(ctx context.Context, name string) (user.User, error)
+	// CreateWithIdentity creates a user bound to a token issuer and subject.
+	CreateWithIdentity(ctx context.Context, name, role, issuer, subject string) (user.User, error)
+	GetByIdentity(ctx context.Context, issuer, subject string) (user.User, error)
+	GetByApiKeyHash(ctx context.Context, keyHash string) (user.User, error)
+	CreateApiKey(ctx context.Context, userId int, keyHash, prefix string) (user.ApiKey, error)
+}
+
+type userRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewUserRepository(pool *pgxpool.Pool) UserRepository {
+	return &userRepository{pool: pool}
+}
+
+func (r *userRepository) Create(ctx context.Context, name, role string) (user.User, error) {
+	sql, args, err := sq.
+		Insert("users").Columns("name", "role").Values(name, role).
+		Suffix("returning id, name, created_at, role").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	u, err := scanUser(r.pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return user.User{}, user.ErrNameTaken
+		}
+		return user.User{}, err
+	}
+
+	return u, nil
+}
+
+func (r *userRepository) GetByName(ctx context.Context, name string) (user.User, error) {
+	sql, args, err := sq.
+		Select("id", "name", "created_at", "role").From("users").
+		Where(sq.Eq{"name": name}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	u, err := scanUser(r.pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.User{}, user.ErrNotFound
+		}
+		return user.User{}, err
+	}
+
+	return u, nil
+}
+
+func (r *userRepository) CreateWithIdentity(
+	ctx context.Context,
+	name, role, issuer, subject string,
+) (user.User, error) {
+	sql, args, err := sq.
+		Insert("users").Columns("name", "role", "issuer", "subject").
+		Values(name, role, issuer, subject).
+		Suffix("returning id, name, created_at, role").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	u, err := scanUser(r.pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		switch {
+		case isConstraintViolation(err, "users_identity_key"):
+			return user.User{}, user.ErrIdentityTaken
+		case isUniqueViolation(err):
+			return user.User{}, user.ErrNameTaken
+		}
+		return user.User{}, err
+	}
+
+	return u, nil
+}
+
+func (r *userRepository) GetByIdentity(ctx context.Context, issuer, subject string) (user.User, error) {
+	sql, args, err := sq.
+		Select("id", "name", "created_at", "role").From("users").
+		Where(sq.Eq{"issuer": issuer, "subject": subject}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	u, err := scanUser(r.pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.User{}, user.ErrNotFound
+		}
+		return user.User{}, err
+	}
+
+	return u, nil
+}
+
+func (r *userRepository) GetByApiKeyHash(ctx context.Context, keyHash string) (user.User, error) {
+	sql, args, err := sq.
+		Select("u.id", "u.name", "u.created_at", "u.role").From("api_keys k").
+		Join("users u on u.id = k.user_id").
+		Where(sq.Eq{"k.key_hash": keyHash, "k.revoked_at": nil}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	u, err := scanUser(r.pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.User{}, user.ErrNotFound
+		}
+		return user.User{}, err
+	}
+
+	return u, nil
+}
+
+func (r *userRepository) CreateApiKey(
+	ctx context.Context,
+	userId int,
+	keyHash, prefix string,
+) (user.ApiKey, error) {
+	sql, args, err := sq.
+		Insert("api_keys").Columns("user_id", "key_hash", "prefix").
+		Values(userId, keyHash, prefix).
+		Suffix("returning id, user_id, prefix, created_at").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return user.ApiKey{}, err
+	}
+
+	var k user.ApiKey
+	err = r.pool.QueryRow(ctx, sql, args...).Scan(&k.Id, &k.UserId, &k.Prefix, &k.CreatedAt)
+	if err != nil {
+		return user.ApiKey{}, err
+	}
+
+	return k, nil
+}
+
+func scanUser(row pgx.Row) (user.User, error) {
+	var u user.User
+	var role string
+	if err := row.Scan(&u.Id, &u.Name, &u.CreatedAt, &role); err != nil {
+		return user.User{}, err
+	}
+	u.Roles = []string{role}
+
+	return u, nil
+}
@@ -0,0 +1,184 @@
+package repositiries
+
+import (
+	"awesomeProject/internal/domain/workspace"
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// workspaceColumns reports the workspace's first domain as its default one.
+var workspaceColumns = []string{
+	"w.id",
+	"w.name",
+	"(select d.host from domains d where d.workspace_id = w.id order by d.id limit 1)",
+	"w.created_at",
+}
+
+type WorkspaceRepository interface {
+	// Create inserts the workspace, registers domain for it unless empty and
+	// makes creatorId its first member.
+	Create(ctx context.Context, name, domain string, creatorId int) (workspace.Workspace, error)
+	GetByName(ctx context.Context, name string) (workspace.Workspace, error)
+	// ListForUser returns the workspaces userId is a member of, or all of
+	// them for a userId of 0.
+	ListForUser(ctx context.Context, userId int) ([]workspace.Workspace, error)
+	AddMember(ctx context.Context, workspaceId, userId int) error
+	IsMember(ctx context.Context, workspaceId, userId int) (bool, error)
+}
+
+type workspaceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWorkspaceRepository(pool *pgxpool.Pool) WorkspaceRepository {
+	return &workspaceRepository{pool: pool}
+}
+
+func (r *workspaceRepository) Create(
+	ctx context.Context,
+	name, domain string,
+	creatorId int,
+) (workspace.Workspace, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return workspace.Workspace{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := sq.
+		Insert("workspaces").Columns("name").Values(name).
+		Suffix("returning id, name, null, created_at").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return workspace.Workspace{}, err
+	}
+
+	w, err := scanWorkspace(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return workspace.Workspace{}, workspace.ErrNameTaken
+		}
+		return workspace.Workspace{}, err
+	}
+
+	if domain != "" {
+		d, err := createDomain(ctx, tx, domain, w.Id)
+		if err != nil {
+			return workspace.Workspace{}, err
+		}
+		w.Domain = d.Host
+	}
+
+	sql, args, err = sq.
+		Insert("workspace_members").Columns("workspace_id", "user_id").Values(w.Id, creatorId).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return workspace.Workspace{}, err
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return workspace.Workspace{}, err
+	}
+
+	return w, tx.Commit(ctx)
+}
+
+func (r *workspaceRepository) GetByName(ctx context.Context, name string) (workspace.Workspace, error) {
+	sql, args, err := sq.
+		Select(workspaceColumns...).From("workspaces w").
+		Where(sq.Eq{"w.name": name}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return workspace.Workspace{}, err
+	}
+
+	w, err := scanWorkspace(r.pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return workspace.Workspace{}, workspace.ErrNotFound
+		}
+		return workspace.Workspace{}, err
+	}
+
+	return w, nil
+}
+
+func (r *workspaceRepository) ListForUser(ctx context.Context, userId int) ([]workspace.Workspace, error) {
+	builder := sq.Select(workspaceColumns...).From("workspaces w").OrderBy("w.name")
+	if userId != 0 {
+		builder = builder.
+			Join("workspace_members m on m.workspace_id = w.id").
+			Where(sq.Eq{"m.user_id": userId})
+	}
+	sql, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var workspaces []workspace.Workspace
+	for rows.Next() {
+		w, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return workspaces, nil
+}
+
+func (r *workspaceRepository) AddMember(ctx context.Context, workspaceId, userId int) error {
+	sql, args, err := sq.
+		Insert("workspace_members").Columns("workspace_id", "user_id").Values(workspaceId, userId).
+		Suffix("on conflict do nothing").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(ctx, sql, args...)
+	return err
+}
+
+func (r *workspaceRepository) IsMember(ctx context.Context, workspaceId, userId int) (bool, error) {
+	sql, args, err := sq.
+		Select("1").From("workspace_members").
+		Where(sq.Eq{"workspace_id": workspaceId, "user_id": userId}).
+		Prefix("select exists (").Suffix(")").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var member bool
+	if err := r.pool.QueryRow(ctx, sql, args...).Scan(&member); err != nil {
+		return false, err
+	}
+
+	return member, nil
+}
+
+func scanWorkspace(row pgx.Row) (workspace.Workspace, error) {
+	var w workspace.Workspace
+	var domain *string
+	if err := row.Scan(&w.Id, &w.Name, &domain, &w.CreatedAt); err != nil {
+		return workspace.Workspace{}, err
+	}
+	if domain != nil {
+		w.Domain = *domain
+	}
+
+	return w, nil
+}
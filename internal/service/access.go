@@ -0,0 +1,224 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/click"
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/domain/workspace"
+	"context"
+	"time"
+)
+
+// roleRank orders roles so that each one includes the permissions of those
+// below it.
+var roleRank = map[string]int{
+	user.RoleViewer: 1,
+	user.RoleEditor: 2,
+	user.RoleAdmin:  3,
+}
+
+// authorize fails unless the caller in ctx holds minRole or a role above it.
+func authorize(ctx context.Context, minRole string) error {
+	u, ok := user.FromContext(ctx)
+	if !ok {
+		return user.ErrUnauthorized
+	}
+
+	for _, r := range u.Roles {
+		if roleRank[r] >= roleRank[minRole] {
+			return nil
+		}
+	}
+
+	return user.ErrForbidden
+}
+
+// urlAccessPolicy enforces role permissions in front of a UrlService. Which
+// links a permitted caller may touch is still decided by the service's owner
+// scoping.
+type urlAccessPolicy struct {
+	next UrlService
+}
+
+func NewUrlAccessPolicy(next UrlService) UrlService {
+	return &urlAccessPolicy{next: next}
+}
+
+func (p *urlAccessPolicy) Save(
+	ctx context.Context,
+	host, urlToSave, alias string,
+	expiresAt time.Time,
+	details url.Details,
+) (url.Url, error) {
+	if err := authorize(ctx, user.RoleEditor); err != nil {
+		return url.Url{}, err
+	}
+	return p.next.Save(ctx, host, urlToSave, alias, expiresAt, details)
+}
+
+func (p *urlAccessPolicy) List(ctx context.Context, q url.ListQuery, cursor string) (url.Page, error) {
+	if err := authorize(ctx, user.RoleViewer); err != nil {
+		return url.Page{}, err
+	}
+	return p.next.List(ctx, q, cursor)
+}
+
+func (p *urlAccessPolicy) Search(ctx context.Context, query string, limit int) ([]url.SearchHit, error) {
+	if err := authorize(ctx, user.RoleViewer); err != nil {
+		return nil, err
+	}
+	return p.next.Search(ctx, query, limit)
+}
+
+func (p *urlAccessPolicy) Get(ctx context.Context, id int) (url.Url, error) {
+	if err := authorize(ctx, user.RoleViewer); err != nil {
+		return url.Url{}, err
+	}
+	return p.next.Get(ctx, id)
+}
+
+// GetByAlias serves public redirects and needs no caller.
+func (p *urlAccessPolicy) GetByAlias(ctx context.Context, host, alias string) (url.Url, error) {
+	return p.next.GetByAlias(ctx, host, alias)
+}
+
+func (p *urlAccessPolicy) ShortUrl(u url.Url) string {
+	return p.next.ShortUrl(u)
+}
+
+func (p *urlAccessPolicy) Update(
+	ctx context.Context,
+	id int,
+	newUrl, alias string,
+	expiresAt time.Time,
+	details url.DetailsPatch,
+) error {
+	if err := authorize(ctx, user.RoleEditor); err != nil {
+		return err
+	}
+	return p.next.Update(ctx, id, newUrl, alias, expiresAt, details)
+}
+
+func (p *urlAccessPolicy) Delete(ctx context.Context, id int) error {
+	if err := authorize(ctx, user.RoleEditor); err != nil {
+		return err
+	}
+	return p.next.Delete(ctx, id)
+}
+
+func (p *urlAccessPolicy) SaveMany(ctx context.Context, drafts []url.Draft) ([]url.ItemResult, error) {
+	if err := authorize(ctx, user.RoleEditor); err != nil {
+		return nil, err
+	}
+	return p.next.SaveMany(ctx, drafts)
+}
+
+func (p *urlAccessPolicy) UpdateMany(ctx context.Context, changes []url.Change) ([]url.ItemResult, error) {
+	if err := authorize(ctx, user.RoleEditor); err != nil {
+		return nil, err
+	}
+	return p.next.UpdateMany(ctx, changes)
+}
+
+func (p *urlAccessPolicy) DeleteMany(ctx context.Context, ids []int) ([]url.ItemResult, error) {
+	if err := authorize(ctx, user.RoleEditor); err != nil {
+		return nil, err
+	}
+	return p.next.DeleteMany(ctx, ids)
+}
+
+func (p *urlAccessPolicy) CheckMany(ctx context.Context, drafts []url.Draft) ([]url.ItemResult, error) {
+	if err := authorize(ctx, user.RoleEditor); err != nil {
+		return nil, err
+	}
+	return p.next.CheckMany(ctx, drafts)
+}
+
+func (p *urlAccessPolicy) Export(ctx context.Context, f url.ListFilter, fn func(url.Url) error) error {
+	if err := authorize(ctx, user.RoleViewer); err != nil {
+		return err
+	}
+	return p.next.Export(ctx, f, fn)
+}
+
+type statsAccessPolicy struct {
+	next StatsService
+}
+
+func NewStatsAccessPolicy(next StatsService) StatsService {
+	return &statsAccessPolicy{next: next}
+}
+
+func (p *statsAccessPolicy) Stats(
+	ctx context.Context,
+	host, alias string,
+	from, to time.Time,
+	interval string,
+) (click.Stats, error) {
+	if err := authorize(ctx, user.RoleViewer); err != nil {
+		return click.Stats{}, err
+	}
+	return p.next.Stats(ctx, host, alias, from, to, interval)
+}
+
+type workspaceAccessPolicy struct {
+	next WorkspaceService
+}
+
+// NewWorkspaceAccessPolicy lets any authenticated role list and enter its
+// workspaces while only admins create workspaces and manage membership.
+func NewWorkspaceAccessPolicy(next WorkspaceService) WorkspaceService {
+	return &workspaceAccessPolicy{next: next}
+}
+
+func (p *workspaceAccessPolicy) Create(ctx context.Context, name, domain string) (workspace.Workspace, error) {
+	if err := authorize(ctx, user.RoleAdmin); err != nil {
+		return workspace.Workspace{}, err
+	}
+	return p.next.Create(ctx, name, domain)
+}
+
+func (p *workspaceAccessPolicy) List(ctx context.Context) ([]workspace.Workspace, error) {
+	if err := authorize(ctx, user.RoleViewer); err != nil {
+		return nil, err
+	}
+	return p.next.List(ctx)
+}
+
+func (p *workspaceAccessPolicy) AddMember(ctx context.Context, workspaceName, userName string) error {
+	if err := authorize(ctx, user.RoleAdmin); err != nil {
+		return err
+	}
+	return p.next.AddMember(ctx, workspaceName, userName)
+}
+
+func (p *workspaceAccessPolicy) Enter(ctx context.Context, name string) (workspace.Workspace, error) {
+	if err := authorize(ctx, user.RoleViewer); err != nil {
+		return workspace.Workspace{}, err
+	}
+	return p.next.Enter(ctx, name)
+}
+
+type domainAccessPolicy struct {
+	next DomainService
+}
+
+// NewDomainAccessPolicy lets any authenticated role list usable domains while
+// only admins register new ones.
+func NewDomainAccessPolicy(next DomainService) DomainService {
+	return &domainAccessPolicy{next: next}
+}
+
+func (p *domainAccessPolicy) Create(ctx context.Context, host, workspaceName string) (url.Domain, error) {
+	if err := authorize(ctx, user.RoleAdmin); err != nil {
+		return url.Domain{}, err
+	}
+	return p.next.Create(ctx, host, workspaceName)
+}
+
+func (p *domainAccessPolicy) List(ctx context.Context) ([]url.Domain, error) {
+	if err := authorize(ctx, user.RoleViewer); err != nil {
+		return nil, err
+	}
+	return p.next.List(ctx)
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/click"
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/user"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordingUrlService counts calls that made it past the policy.
+type recordingUrlService struct {
+	calls int
+}
+
+func (s *recordingUrlService) Save(
+	ctx context.Context,
+	host, urlToSave, alias string,
+	expiresAt time.Time,
+	details url.Details,
+) (url.Url, error) {
+	s.calls++
+	return url.Url{}, nil
+}
+
+func (s *recordingUrlService) List(ctx context.Context, q url.ListQuery, cursor string) (url.Page, error) {
+	s.calls++
+	return url.Page{}, nil
+}
+
+func (s *recordingUrlService) Search(ctx context.Context, query string, limit int) ([]url.SearchHit, error) {
+	s.calls++
+	return nil, nil
+}
+
+func (s *recordingUrlService) Get(ctx context.Context, id int) (url.Url, error) {
+	s.calls++
+	return url.Url{}, nil
+}
+
+func (s *recordingUrlService) GetByAlias(ctx context.Context, host, alias string) (url.Url, error) {
+	s.calls++
+	return url.Url{}, nil
+}
+
+func (s *recordingUrlService) ShortUrl(u url.Url) string {
+	return u.Alias
+}
+
+func (s *recordingUrlService) Update(
+	ctx context.Context,
+	id int,
+	newUrl, alias string,
+	expiresAt time.Time,
+	details url.DetailsPatch,
+) error {
+	s.calls++
+	return nil
+}
+
+func (s *recordingUrlService) Delete(ctx context.Context, id int) error {
+	s.calls++
+	return nil
+}
+
+func (s *recordingUrlService) SaveMany(ctx context.Context, drafts []url.Draft) ([]url.ItemResult, error) {
+	s.calls++
+	return nil, nil
+}
+
+func (s *recordingUrlService) UpdateMany(ctx context.Context, changes []url.Change) ([]url.ItemResult, error) {
+	s.calls++
+	return nil, nil
+}
+
+func (s *recordingUrlService) DeleteMany(ctx context.Context, ids []int) ([]url.ItemResult, error) {
+	s.calls++
+	return nil, nil
+}
+
+func (s *recordingUrlService) CheckMany(ctx context.Context, drafts []url.Draft) ([]url.ItemResult, error) {
+	s.calls++
+	return nil, nil
+}
+
+func (s *recordingUrlService) Export(ctx context.Context, f url.ListFilter, fn func(url.Url) error) error {
+	s.calls++
+	return nil
+}
+
+type recordingStatsService struct {
+	calls int
+}
+
+func (s *recordingStatsService) Stats(ctx context.Context, host, alias string, from, to time.Time, interval string) (click.Stats, error) {
+	s.calls++
+	return click.Stats{}, nil
+}
+
+func TestAccessPolicy(t *testing.T) {
+	ops := map[string]func(ctx context.Context, urls UrlService, stats StatsService) error{
+		"save": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			_, err := urls.Save(ctx, "", "https://example.com", "", time.Time{}, url.Details{})
+			return err
+		},
+		"list": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			_, err := urls.List(ctx, url.ListQuery{}, "")
+			return err
+		},
+		"search": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			_, err := urls.Search(ctx, "newsletter", 0)
+			return err
+		},
+		"get": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			_, err := urls.Get(ctx, 1)
+			return err
+		},
+		"update": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			return urls.Update(ctx, 1, "https://example.com", "", time.Time{}, url.DetailsPatch{})
+		},
+		"delete": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			return urls.Delete(ctx, 1)
+		},
+		"bulk save": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			_, err := urls.SaveMany(ctx, nil)
+			return err
+		},
+		"bulk update": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			_, err := urls.UpdateMany(ctx, nil)
+			return err
+		},
+		"bulk delete": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			_, err := urls.DeleteMany(ctx, nil)
+			return err
+		},
+		"check": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			_, err := urls.CheckMany(ctx, nil)
+			return err
+		},
+		"export": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			return urls.Export(ctx, url.ListFilter{}, func(url.Url) error { return nil })
+		},
+		"stats": func(ctx context.Context, _ UrlService, stats StatsService) error {
+			_, err := stats.Stats(ctx, "", "abc", time.Now().Add(-time.Hour), time.Now(), click.IntervalHour)
+			return err
+		},
+		"redirect": func(ctx context.Context, urls UrlService, _ StatsService) error {
+			_, err := urls.GetByAlias(ctx, "", "abc")
+			return err
+		},
+	}
+
+	tests := []struct {
+		name    string
+		caller  *user.User
+		op      string
+		wantErr error
+	}{
+		{"anonymous redirect", nil, "redirect", nil},
+		{"anonymous list", nil, "list", user.ErrUnauthorized},
+		{"anonymous save", nil, "save", user.ErrUnauthorized},
+		{"anonymous search", nil, "search", user.ErrUnauthorized},
+		{"anonymous export", nil, "export", user.ErrUnauthorized},
+
+		{"no role list", &user.User{Id: 1}, "list", user.ErrForbidden},
+		{"unknown role list", &user.User{Id: 1, Roles: []string{"owner"}}, "list", user.ErrForbidden},
+
+		{"viewer list", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "list", nil},
+		{"viewer get", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "get", nil},
+		{"viewer stats", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "stats", nil},
+		{"viewer search", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "search", nil},
+		{"viewer save", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "save", user.ErrForbidden},
+		{"viewer update", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "update", user.ErrForbidden},
+		{"viewer delete", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "delete", user.ErrForbidden},
+		{"viewer bulk save", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "bulk save", user.ErrForbidden},
+		{"viewer bulk update", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "bulk update", user.ErrForbidden},
+		{"viewer bulk delete", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "bulk delete", user.ErrForbidden},
+		{"viewer check", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "check", user.ErrForbidden},
+		{"viewer export", &user.User{Id: 1, Roles: []string{user.RoleViewer}}, "export", nil},
+
+		{"editor list", &user.User{Id: 1, Roles: []string{user.RoleEditor}}, "list", nil},
+		{"editor stats", &user.User{Id: 1, Roles: []string{user.RoleEditor}}, "stats", nil},
+		{"editor save", &user.User{Id: 1, Roles: []string{user.RoleEditor}}, "save", nil},
+		{"editor update", &user.User{Id: 1, Roles: []string{user.RoleEditor}}, "update", nil},
+		{"editor delete", &user.User{Id: 1, Roles: []string{user.RoleEditor}}, "delete", nil},
+		{"editor bulk save", &user.User{Id: 1, Roles: []string{user.RoleEditor}}, "bulk save", nil},
+		{"editor bulk update", &user.User{Id: 1, Roles: []string{user.RoleEditor}}, "bulk update", nil},
+		{"editor bulk delete", &user.User{Id: 1, Roles: []string{user.RoleEditor}}, "bulk delete", nil},
+		{"editor check", &user.User{Id: 1, Roles: []string{user.RoleEditor}}, "check", nil},
+
+		{"admin save", &user.User{Id: 1, Roles: []string{user.RoleAdmin}}, "save", nil},
+		{"admin delete", &user.User{Id: 1, Roles: []string{user.RoleAdmin}}, "delete", nil},
+		{"admin stats", &user.User{Id: 1, Roles: []string{user.RoleAdmin}}, "stats", nil},
+
+		{"highest role wins", &user.User{Id: 1, Roles: []string{user.RoleViewer, user.RoleEditor}}, "save", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.caller != nil {
+				ctx = user.WithUser(ctx, *tt.caller)
+			}
+			urls := &recordingUrlService{}
+			stats := &recordingStatsService{}
+
+			err := ops[tt.op](ctx, NewUrlAccessPolicy(urls), NewStatsAccessPolicy(stats))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got: %v", tt.wantErr, err)
+			}
+
+			calls := urls.calls + stats.calls
+			if tt.wantErr == nil && calls != 1 {
+				t.Errorf("expected the call to reach the service, got %d calls", calls)
+			}
+			if tt.wantErr != nil && calls != 0 {
+				t.Errorf("expected the call to be stopped by the policy, got %d calls", calls)
+			}
+		})
+	}
+}
+
+func TestAccessPolicy_ReadScope(t *testing.T) {
+	viewer := user.User{Id: 1, Roles: []string{user.RoleViewer}}
+	editor := user.User{Id: 1, Roles: []string{user.RoleEditor}}
+	admin := user.User{Id: 1, Roles: []string{user.RoleAdmin}}
+	own := url.Url{Id: 4, Alias: "mine", OwnerId: 1}
+	theirs := url.Url{Id: 5, Alias: "theirs", OwnerId: 2}
+	inWorkspace := url.Url{Id: 6, Alias: "team", OwnerId: 1, WorkspaceId: 7}
+
+	tests := []struct {
+		name      string
+		caller    user.User
+		link      url.Url
+		wantErr   error
+		wantScope url.Scope
+	}{
+		{"viewer sees its own links", viewer, own, nil, url.Scope{OwnerId: 1}},
+		{"viewer does not see other owners' links", viewer, theirs, url.ErrNotFound, url.Scope{OwnerId: 1}},
+		{"viewer does not see workspace links outside it", viewer, inWorkspace, url.ErrNotFound, url.Scope{OwnerId: 1}},
+		{"editor does not see other owners' links", editor, theirs, url.ErrNotFound, url.Scope{OwnerId: 1}},
+		{"admin sees other owners' links", admin, theirs, nil, url.Scope{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{
+				getFn: func(ctx context.Context, id int) (url.Url, error) {
+					return tt.link, nil
+				},
+				listFn: func(ctx context.Context) ([]url.Url, error) {
+					return []url.Url{tt.link}, nil
+				},
+			}
+			urls := NewUrlAccessPolicy(
+				NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false),
+			)
+			ctx := user.WithUser(context.Background(), tt.caller)
+
+			u, err := urls.Get(ctx, tt.link.Id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get: expected %v, got: %v", tt.wantErr, err)
+			}
+			if err == nil && u.Alias != tt.link.Alias {
+				t.Errorf("Get: expected link %q, got %+v", tt.link.Alias, u)
+			}
+
+			page, err := urls.List(ctx, url.ListQuery{}, "")
+			if err != nil {
+				t.Fatalf("List: expected no error, got: %v", err)
+			}
+			if repo.scope != tt.wantScope {
+				t.Errorf("List: expected scope %+v, got %+v", tt.wantScope, repo.scope)
+			}
+			if tt.wantErr == nil && len(page.Urls) != 1 {
+				t.Errorf("List: expected the link listed, got %+v", page.Urls)
+			}
+		})
+	}
+}
+
+func TestWorkspaceAccessPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   []string
+		wantErr error
+	}{
+		{"viewer", []string{user.RoleViewer}, user.ErrForbidden},
+		{"editor", []string{user.RoleEditor}, user.ErrForbidden},
+		{"admin", []string{user.RoleAdmin}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockWorkspaceRepo{}
+			svc := NewWorkspaceAccessPolicy(NewWorkspaceService(repo, &mockUserRepo{}))
+			ctx := user.WithUser(context.Background(), user.User{Id: 1, Roles: tt.roles})
+
+			if _, err := svc.Create(ctx, "docs", ""); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Create: expected %v, got: %v", tt.wantErr, err)
+			}
+			if _, err := svc.List(ctx); err != nil {
+				t.Errorf("List: expected no error, got: %v", err)
+			}
+		})
+	}
+}
@@ -1,24 +1,135 @@
 package service
 
-import "math/rand"
+import (
+	"awesomeProject/internal/repositiries"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+const (
+	defaultAliasLength = 8
+
+	GeneratorRandom   = "random"
+	GeneratorSequence = "sequence"
+	GeneratorHashids  = "hashids"
+	GeneratorWords    = "words"
+	GeneratorBlock    = "block"
+)
+
+const (
+	base62Charset = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	base36Charset = "0123456789abcdefghijklmnopqrstuvwxyz"
+)
 
 type AliasGenerator interface {
-	Generate() string
+	Generate(ctx context.Context) (string, error)
+}
+
+// ResizableGenerator is implemented by generators whose output can be made
+// longer when the keyspace at the default length is getting crowded. extra is
+// measured in the generator's own units (characters, words).
+type ResizableGenerator interface {
+	AliasGenerator
+	GenerateLonger(ctx context.Context, extra int) (string, error)
+}
+
+// GeneratorConfig selects and tunes an alias generation strategy.
+type GeneratorConfig struct {
+	Strategy  string
+	MinLength int
+	Salt      string
+	Words     int
+	BlockSize int64
+	// Lowercase draws generated aliases from lowercase letters and digits
+	// only, as case-insensitive alias policies require: lowercasing base62
+	// output would map distinct ids onto the same alias.
+	Lowercase bool
+}
+
+// NewAliasGenerator builds the generator named by cfg.Strategy. Sequence-based
+// strategies draw ids from seq and the block strategy from counter; either may
+// be nil when the chosen strategy does not need it.
+func NewAliasGenerator(
+	cfg GeneratorConfig,
+	seq repositiries.SequenceRepository,
+	counter repositiries.CounterRepository,
+) (AliasGenerator, error) {
+	alphabet := base62Charset
+	if cfg.Lowercase {
+		alphabet = base36Charset
+	}
+
+	switch cfg.Strategy {
+	case GeneratorRandom, "":
+		return NewRandomGenerator(alphabet), nil
+	case GeneratorSequence:
+		return NewSequenceGenerator(seq, alphabet, cfg.MinLength), nil
+	case GeneratorHashids:
+		return NewHashidsGenerator(seq, cfg.Salt, alphabet, cfg.MinLength), nil
+	case GeneratorWords:
+		return NewWordsGenerator(defaultWords, cfg.Words, "-"), nil
+	case GeneratorBlock:
+		if cfg.BlockSize <= 0 {
+			return nil, fmt.Errorf("block size must be positive, got %d", cfg.BlockSize)
+		}
+		encode := func(id uint64) string {
+			return encodeBase62(id, alphabet, cfg.MinLength)
+		}
+		if cfg.Salt != "" {
+			encode = newHashidsEncoder(cfg.Salt, alphabet, cfg.MinLength).encode
+		}
+		return NewBlockGenerator(counter, cfg.BlockSize, encode), nil
+	default:
+		return nil, fmt.Errorf("unknown alias generator %q", cfg.Strategy)
+	}
+}
+
+// randomGenerator draws characters of alphabet from crypto/rand.
+type randomGenerator struct {
+	alphabet string
 }
 
-type aliasGenerator struct{}
+func NewRandomGenerator(alphabet string) ResizableGenerator {
+	return &randomGenerator{alphabet: alphabet}
+}
 
-func NewAliasGenerator() AliasGenerator {
-	return &aliasGenerator{}
+func (g *randomGenerator) Generate(ctx context.Context) (string, error) {
+	return g.GenerateLonger(ctx, 0)
 }
 
-func (g *aliasGenerator) Generate() string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	const length = 8
+func (g *randomGenerator) GenerateLonger(_ context.Context, extra int) (string, error) {
+	max := big.NewInt(int64(len(g.alphabet)))
 
-	b := make([]byte, length)
+	b := make([]byte, defaultAliasLength+extra)
 	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = g.alphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// encodeBase62 encodes n using alphabet, left-padding with alphabet[0] up to
+// minLength. Encodings never start with alphabet[0] unless padded, so padding
+// keeps the mapping one-to-one.
+func encodeBase62(n uint64, alphabet string, minLength int) string {
+	base := uint64(len(alphabet))
+
+	var b []byte
+	for n > 0 {
+		b = append(b, alphabet[n%base])
+		n /= base
+	}
+	for len(b) < minLength || len(b) == 0 {
+		b = append(b, alphabet[0])
+	}
+
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
 	}
 	return string(b)
 }
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"awesomeProject/internal/repositiries"
+	"context"
+	"sync"
+)
+
+const blockCounterName = "url"
+
+// blockGenerator hands out ids from blocks reserved in a shared Postgres
+// counter. Each replica reserves its own block, so ids never overlap across
+// processes and only one round trip is needed per blockSize aliases. Since ids
+// are never reissued, Save only retries when a custom alias already happens to
+// occupy a generated code.
+type blockGenerator struct {
+	counter   repositiries.CounterRepository
+	blockSize int64
+	encode    func(id uint64) string
+
+	mu   sync.Mutex
+	next int64
+	end  int64
+}
+
+func NewBlockGenerator(
+	counter repositiries.CounterRepository,
+	blockSize int64,
+	encode func(id uint64) string,
+) AliasGenerator {
+	return &blockGenerator{
+		counter:   counter,
+		blockSize: blockSize,
+		encode:    encode,
+	}
+}
+
+func (g *blockGenerator) Generate(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.next >= g.end {
+		start, err := g.counter.Reserve(ctx, blockCounterName, g.blockSize)
+		if err != nil {
+			return "", err
+		}
+		g.next, g.end = start, start+g.blockSize
+	}
+
+	id := g.next
+	g.next++
+	return g.encode(uint64(id)), nil
+}
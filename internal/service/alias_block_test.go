@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type mockCounter struct {
+	mu       sync.Mutex
+	next     int64
+	reserves int
+	err      error
+}
+
+func (m *mockCounter) Reserve(ctx context.Context, name string, size int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return 0, m.err
+	}
+	if m.next == 0 {
+		m.next = 1
+	}
+	start := m.next
+	m.next += size
+	m.reserves++
+	return start, nil
+}
+
+func decimal(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
+
+func TestBlockGenerator_ReservesOncePerBlock(t *testing.T) {
+	counter := &mockCounter{}
+	g := NewBlockGenerator(counter, 100, decimal)
+
+	for i := 1; i <= 250; i++ {
+		alias, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if alias != strconv.Itoa(i) {
+			t.Fatalf("expected id %d, got %s", i, alias)
+		}
+	}
+
+	if counter.reserves != 3 {
+		t.Errorf("expected 3 block reservations, got %d", counter.reserves)
+	}
+}
+
+func TestBlockGenerator_ReplicasNeverOverlap(t *testing.T) {
+	counter := &mockCounter{}
+	replicas := []AliasGenerator{
+		NewBlockGenerator(counter, 50, decimal),
+		NewBlockGenerator(counter, 50, decimal),
+		NewBlockGenerator(counter, 50, decimal),
+	}
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+	for _, g := range replicas {
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(g AliasGenerator) {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					alias, err := g.Generate(context.Background())
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					mu.Lock()
+					if seen[alias] {
+						t.Errorf("duplicate alias %s", alias)
+					}
+					seen[alias] = true
+					mu.Unlock()
+				}
+			}(g)
+		}
+	}
+	wg.Wait()
+
+	if len(seen) != 3*4*500 {
+		t.Errorf("expected %d unique aliases, got %d", 3*4*500, len(seen))
+	}
+}
+
+func TestBlockGenerator_ReserveError(t *testing.T) {
+	counterErr := errors.New("db error")
+	g := NewBlockGenerator(&mockCounter{err: counterErr}, 10, decimal)
+
+	if _, err := g.Generate(context.Background()); !errors.Is(err, counterErr) {
+		t.Errorf("expected db error, got: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"fmt"
+	"strings"
+)
+
+// AliasPolicy describes which custom aliases callers may choose.
+type AliasPolicy struct {
+	MinLength     int
+	MaxLength     int
+	Charset       string
+	Reserved      []string
+	CaseSensitive bool
+}
+
+// Normalize applies the policy's case mode to an alias without validating it.
+// Lookups must use the same form the alias was stored in.
+func (p AliasPolicy) Normalize(alias string) string {
+	if p.CaseSensitive {
+		return alias
+	}
+	return strings.ToLower(alias)
+}
+
+// Validate checks a caller-supplied alias and returns it in normalized form.
+// Errors wrap url.ErrInvalidAlias and carry a human-readable reason.
+func (p AliasPolicy) Validate(alias string) (string, error) {
+	alias = p.Normalize(alias)
+
+	n := len([]rune(alias))
+	if n < p.MinLength || n > p.MaxLength {
+		return "", fmt.Errorf(
+			"%w: length must be between %d and %d characters",
+			url.ErrInvalidAlias, p.MinLength, p.MaxLength,
+		)
+	}
+
+	for _, r := range alias {
+		if !strings.ContainsRune(p.Charset, r) {
+			return "", fmt.Errorf("%w: character %q is not allowed", url.ErrInvalidAlias, r)
+		}
+	}
+
+	if p.IsReserved(alias) {
+		return "", fmt.Errorf("%w: %q is reserved", url.ErrInvalidAlias, alias)
+	}
+
+	return alias, nil
+}
+
+// IsReserved reports whether alias matches a reserved word, ignoring case.
+func (p AliasPolicy) IsReserved(alias string) bool {
+	for _, word := range p.Reserved {
+		if strings.EqualFold(alias, word) {
+			return true
+		}
+	}
+	return false
+}
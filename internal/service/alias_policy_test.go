@@ -0,0 +1,47 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"errors"
+	"testing"
+)
+
+func TestAliasPolicy_Validate(t *testing.T) {
+	insensitive := testAliasPolicy
+	insensitive.CaseSensitive = false
+
+	tests := []struct {
+		name    string
+		policy  AliasPolicy
+		alias   string
+		want    string
+		wantErr bool
+	}{
+		{"valid", testAliasPolicy, "My-Alias_1", "My-Alias_1", false},
+		{"too short", testAliasPolicy, "ab", "", true},
+		{"too long", testAliasPolicy, "abcdefghijklmnopqrstuvwxyz0123456789", "", true},
+		{"bad charset", testAliasPolicy, "hello/world", "", true},
+		{"unicode", testAliasPolicy, "привет", "", true},
+		{"reserved", testAliasPolicy, "health", "", true},
+		{"reserved any case", testAliasPolicy, "API", "", true},
+		{"case insensitive lowers", insensitive, "MyAlias", "myalias", false},
+		{"case insensitive reserved", insensitive, "List", "", true},
+	}
+
+	for _, tc := range tests {
+		got, err := tc.policy.Validate(tc.alias)
+		if tc.wantErr {
+			if !errors.Is(err, url.ErrInvalidAlias) {
+				t.Errorf("%s: expected ErrInvalidAlias, got: %v", tc.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
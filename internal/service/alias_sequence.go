@@ -0,0 +1,120 @@
+package service
+
+import (
+	"awesomeProject/internal/repositiries"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// sequenceGenerator encodes ids from a Postgres sequence in alphabet. Every id
+// is issued once, so aliases never collide with each other.
+type sequenceGenerator struct {
+	seq       repositiries.SequenceRepository
+	alphabet  string
+	minLength int
+}
+
+func NewSequenceGenerator(seq repositiries.SequenceRepository, alphabet string, minLength int) AliasGenerator {
+	return &sequenceGenerator{seq: seq, alphabet: alphabet, minLength: minLength}
+}
+
+func (g *sequenceGenerator) Generate(ctx context.Context) (string, error) {
+	id, err := g.seq.Next(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeBase62(uint64(id), g.alphabet, g.minLength), nil
+}
+
+const (
+	feistelRounds   = 4
+	feistelHalfBits = 24
+	feistelHalfMask = 1<<feistelHalfBits - 1
+)
+
+// hashidsGenerator hides the sequential nature of ids: each id is permuted by
+// a salt-keyed Feistel network over 48 bits (a bijection, so still
+// collision-free) and encoded with a salt-shuffled alphabet.
+type hashidsGenerator struct {
+	seq repositiries.SequenceRepository
+	*hashidsEncoder
+}
+
+func NewHashidsGenerator(
+	seq repositiries.SequenceRepository,
+	salt string,
+	alphabet string,
+	minLength int,
+) AliasGenerator {
+	return &hashidsGenerator{
+		seq:            seq,
+		hashidsEncoder: newHashidsEncoder(salt, alphabet, minLength),
+	}
+}
+
+func (g *hashidsGenerator) Generate(ctx context.Context) (string, error) {
+	id, err := g.seq.Next(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return g.encode(uint64(id)), nil
+}
+
+type hashidsEncoder struct {
+	minLength int
+	alphabet  string
+	keys      [feistelRounds]uint32
+}
+
+func newHashidsEncoder(salt string, alphabet string, minLength int) *hashidsEncoder {
+	sum := sha256.Sum256([]byte(salt))
+
+	e := &hashidsEncoder{
+		minLength: minLength,
+		alphabet:  shuffleAlphabet(alphabet, sum[:]),
+	}
+	for i := range e.keys {
+		e.keys[i] = binary.BigEndian.Uint32(sum[16+i*4:])
+	}
+	return e
+}
+
+func (e *hashidsEncoder) encode(id uint64) string {
+	return encodeBase62(e.permute(id), e.alphabet, e.minLength)
+}
+
+// permute maps the low 48 bits of id onto another 48-bit value. Ids beyond
+// 2^48 are passed through the high bits unchanged.
+func (e *hashidsEncoder) permute(id uint64) uint64 {
+	high := id &^ (1<<(2*feistelHalfBits) - 1)
+	l := uint32(id>>feistelHalfBits) & feistelHalfMask
+	r := uint32(id) & feistelHalfMask
+
+	for _, k := range e.keys {
+		l, r = r, l^(feistelRound(r, k)&feistelHalfMask)
+	}
+
+	return high | uint64(l)<<feistelHalfBits | uint64(r)
+}
+
+func feistelRound(x, key uint32) uint32 {
+	x ^= key
+	x *= 0x9e3779b1
+	x ^= x >> 15
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	return x
+}
+
+// shuffleAlphabet deterministically permutes alphabet using seed bytes.
+func shuffleAlphabet(alphabet string, seed []byte) string {
+	b := []byte(alphabet)
+	for i := len(b) - 1; i > 0; i-- {
+		j := int(seed[i%len(seed)]) * (i + 1) / 256
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type mockSequence struct {
+	next atomic.Int64
+}
+
+func (m *mockSequence) Next(ctx context.Context) (int64, error) {
+	return m.next.Add(1), nil
+}
+
+func TestNewAliasGenerator_Strategies(t *testing.T) {
+	strategies := []string{"", GeneratorRandom, GeneratorSequence, GeneratorHashids, GeneratorWords, GeneratorBlock}
+	for _, strategy := range strategies {
+		g, err := NewAliasGenerator(
+			GeneratorConfig{Strategy: strategy, MinLength: 6, Words: 3, BlockSize: 10},
+			&mockSequence{},
+			&mockCounter{},
+		)
+		if err != nil {
+			t.Errorf("strategy %q: unexpected error: %v", strategy, err)
+			continue
+		}
+		if _, err := g.Generate(context.Background()); err != nil {
+			t.Errorf("strategy %q: generate failed: %v", strategy, err)
+		}
+	}
+
+	if _, err := NewAliasGenerator(GeneratorConfig{Strategy: "uuid"}, nil, nil); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+	if _, err := NewAliasGenerator(GeneratorConfig{Strategy: GeneratorBlock}, nil, nil); err == nil {
+		t.Error("expected error for zero block size")
+	}
+}
+
+func TestNewAliasGenerator_Lowercase(t *testing.T) {
+	strategies := []string{GeneratorRandom, GeneratorSequence, GeneratorHashids, GeneratorBlock}
+	for _, strategy := range strategies {
+		g, err := NewAliasGenerator(
+			GeneratorConfig{Strategy: strategy, MinLength: 1, BlockSize: 100, Lowercase: true},
+			&mockSequence{},
+			&mockCounter{},
+		)
+		if err != nil {
+			t.Fatalf("strategy %q: unexpected error: %v", strategy, err)
+		}
+
+		// ids past 36 would alias one another if base62 output were
+		// lowercased afterwards
+		seen := make(map[string]bool)
+		for i := 0; i < 5000; i++ {
+			alias, err := g.Generate(context.Background())
+			if err != nil {
+				t.Fatalf("strategy %q: generate failed: %v", strategy, err)
+			}
+			if alias != strings.ToLower(alias) {
+				t.Fatalf("strategy %q: expected a lowercase alias, got %q", strategy, alias)
+			}
+			if seen[alias] {
+				t.Fatalf("strategy %q: duplicate alias %q", strategy, alias)
+			}
+			seen[alias] = true
+		}
+	}
+}
+
+func TestRandomGenerator_LengthAndCharset(t *testing.T) {
+	g := NewRandomGenerator(base62Charset)
+
+	for extra := 0; extra < 3; extra++ {
+		alias, err := g.GenerateLonger(context.Background(), extra)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(alias) != defaultAliasLength+extra {
+			t.Errorf("expected length %d, got %q", defaultAliasLength+extra, alias)
+		}
+		for _, r := range alias {
+			if !strings.ContainsRune(base62Charset, r) {
+				t.Errorf("unexpected character %q in %q", r, alias)
+			}
+		}
+	}
+}
+
+func TestRandomGenerator_Distribution(t *testing.T) {
+	g := NewRandomGenerator(base62Charset)
+	counts := make(map[rune]int)
+
+	const samples = 5000
+	for i := 0; i < samples; i++ {
+		alias, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, r := range alias {
+			counts[r]++
+		}
+	}
+
+	// 40000 characters over 62 symbols: ~645 each. Bounds are loose enough to
+	// never flake but catch a broken or heavily biased source.
+	expected := samples * defaultAliasLength / len(base62Charset)
+	for _, r := range base62Charset {
+		if counts[r] < expected/2 || counts[r] > expected*2 {
+			t.Errorf("character %q drawn %d times, expected about %d", r, counts[r], expected)
+		}
+	}
+}
+
+func TestRandomGenerator_Uniqueness(t *testing.T) {
+	g := NewRandomGenerator(base62Charset)
+	seen := make(map[string]bool)
+
+	for i := 0; i < 10000; i++ {
+		alias, _ := g.Generate(context.Background())
+		if seen[alias] {
+			t.Fatalf("duplicate alias %q after %d draws", alias, i)
+		}
+		seen[alias] = true
+	}
+}
+
+func TestSequenceGenerator_Unique(t *testing.T) {
+	g := NewSequenceGenerator(&mockSequence{}, base62Charset, 4)
+	seen := make(map[string]bool)
+
+	for i := 0; i < 100000; i++ {
+		alias, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(alias) < 4 {
+			t.Fatalf("alias %q shorter than min length", alias)
+		}
+		if seen[alias] {
+			t.Fatalf("duplicate alias %q", alias)
+		}
+		seen[alias] = true
+	}
+}
+
+func TestEncodeBase62(t *testing.T) {
+	tests := []struct {
+		n         uint64
+		minLength int
+		want      string
+	}{
+		{0, 0, "0"},
+		{1, 0, "1"},
+		{61, 0, "Z"},
+		{62, 0, "10"},
+		{62, 4, "0010"},
+		{3843, 2, "ZZ"},
+	}
+
+	for _, tc := range tests {
+		got := encodeBase62(tc.n, base62Charset, tc.minLength)
+		if got != tc.want {
+			t.Errorf("encodeBase62(%d, %d) = %q, want %q", tc.n, tc.minLength, got, tc.want)
+		}
+	}
+}
+
+func TestHashidsGenerator_UniqueAndObfuscated(t *testing.T) {
+	g := NewHashidsGenerator(&mockSequence{}, "pepper", base62Charset, 6)
+	seen := make(map[string]bool)
+
+	var prev string
+	sequentialNeighbours := 0
+	for i := 0; i < 100000; i++ {
+		alias, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if seen[alias] {
+			t.Fatalf("duplicate alias %q", alias)
+		}
+		seen[alias] = true
+		if prev != "" && alias[:len(alias)-1] == prev[:len(prev)-1] {
+			sequentialNeighbours++
+		}
+		prev = alias
+	}
+
+	// consecutive ids must not produce aliases that differ only in the last character
+	if sequentialNeighbours > 100 {
+		t.Errorf("aliases look sequential: %d neighbours share a prefix", sequentialNeighbours)
+	}
+}
+
+func TestHashidsGenerator_SaltChangesOutput(t *testing.T) {
+	a := NewHashidsGenerator(nil, "salt-a", base62Charset, 6).(*hashidsGenerator)
+	b := NewHashidsGenerator(nil, "salt-b", base62Charset, 6).(*hashidsGenerator)
+
+	if a.encode(42) == b.encode(42) {
+		t.Error("expected different salts to produce different aliases")
+	}
+	if a.encode(42) != a.encode(42) {
+		t.Error("expected encoding to be deterministic")
+	}
+}
+
+func TestHashidsGenerator_PermuteIsBijective(t *testing.T) {
+	g := NewHashidsGenerator(nil, "salt", base62Charset, 0).(*hashidsGenerator)
+	seen := make(map[uint64]bool)
+
+	for id := uint64(0); id < 1<<16; id++ {
+		p := g.permute(id)
+		if p >= 1<<48 {
+			t.Fatalf("permute(%d) = %d escapes 48 bits", id, p)
+		}
+		if seen[p] {
+			t.Fatalf("permute collision at id %d", id)
+		}
+		seen[p] = true
+	}
+}
+
+func TestWordsGenerator(t *testing.T) {
+	g := NewWordsGenerator(defaultWords, 3, "-")
+
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		alias, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if parts := strings.Split(alias, "-"); len(parts) != 3 {
+			t.Fatalf("expected 3 words, got %q", alias)
+		}
+		seen[alias] = true
+	}
+	// ~10^8 combinations; more than a few repeats in 1000 draws is suspicious
+	if len(seen) < 995 {
+		t.Errorf("too many repeated aliases: %d unique out of 1000", len(seen))
+	}
+
+	longer, _ := g.GenerateLonger(context.Background(), 1)
+	if parts := strings.Split(longer, "-"); len(parts) != 4 {
+		t.Errorf("expected 4 words, got %q", longer)
+	}
+}
+
+func TestWordsGenerator_FitsAliasPolicy(t *testing.T) {
+	for _, w := range defaultWords {
+		for _, r := range w {
+			if !strings.ContainsRune(testAliasPolicy.Charset, r) {
+				t.Errorf("word %q has character outside policy charset", w)
+			}
+		}
+	}
+}
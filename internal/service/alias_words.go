@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"math/big"
+	"strings"
+)
+
+//go:embed words.txt
+var wordsFile string
+
+var defaultWords = strings.Fields(wordsFile)
+
+// wordsGenerator produces human-readable aliases such as "calm-river-oak" by
+// joining words picked with crypto/rand.
+type wordsGenerator struct {
+	words []string
+	count int
+	sep   string
+}
+
+func NewWordsGenerator(words []string, count int, sep string) ResizableGenerator {
+	return &wordsGenerator{words: words, count: count, sep: sep}
+}
+
+func (g *wordsGenerator) Generate(ctx context.Context) (string, error) {
+	return g.GenerateLonger(ctx, 0)
+}
+
+// GenerateLonger appends extra words to the configured count.
+func (g *wordsGenerator) GenerateLonger(_ context.Context, extra int) (string, error) {
+	max := big.NewInt(int64(len(g.words)))
+
+	picked := make([]string, g.count+extra)
+	for i := range picked {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		picked[i] = g.words[n.Int64()]
+	}
+	return strings.Join(picked, g.sep), nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/user"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type mockUserRepo struct {
+	users      []user.User
+	keys       []user.ApiKey
+	keyOwner   map[string]int    // key hash -> user id
+	identities map[[2]string]int // issuer, subject -> user id
+}
+
+func (m *mockUserRepo) Create(ctx context.Context, name, role string) (user.User, error) {
+	if _, err := m.GetByName(ctx, name); err == nil {
+		return user.User{}, user.ErrNameTaken
+	}
+	u := user.User{Id: len(m.users) + 1, Name: name, Roles: []string{role}}
+	m.users = append(m.users, u)
+	return u, nil
+}
+
+func (m *mockUserRepo) GetByName(ctx context.Context, name string) (user.User, error) {
+	for _, u := range m.users {
+		if u.Name == name {
+			return u, nil
+		}
+	}
+	return user.User{}, user.ErrNotFound
+}
+
+func (m *mockUserRepo) CreateWithIdentity(ctx context.Context, name, role, issuer, subject string) (user.User, error) {
+	if _, err := m.GetByIdentity(ctx, issuer, subject); err == nil {
+		return user.User{}, user.ErrIdentityTaken
+	}
+	u, err := m.Create(ctx, name, role)
+	if err != nil {
+		return user.User{}, err
+	}
+	if m.identities == nil {
+		m.identities = make(map[[2]string]int)
+	}
+	m.identities[[2]string{issuer, subject}] = u.Id
+	return u, nil
+}
+
+func (m *mockUserRepo) GetByIdentity(ctx context.Context, issuer, subject string) (user.User, error) {
+	id, ok := m.identities[[2]string{issuer, subject}]
+	if !ok {
+		return user.User{}, user.ErrNotFound
+	}
+	for _, u := range m.users {
+		if u.Id == id {
+			return u, nil
+		}
+	}
+	return user.User{}, user.ErrNotFound
+}
+
+func (m *mockUserRepo) GetByApiKeyHash(ctx context.Context, keyHash string) (user.User, error) {
+	id, ok := m.keyOwner[keyHash]
+	if !ok {
+		return user.User{}, user.ErrNotFound
+	}
+	return user.User{Id: id}, nil
+}
+
+func (m *mockUserRepo) CreateApiKey(ctx context.Context, userId int, keyHash, prefix string) (user.ApiKey, error) {
+	if m.keyOwner == nil {
+		m.keyOwner = make(map[string]int)
+	}
+	m.keyOwner[keyHash] = userId
+	k := user.ApiKey{Id: len(m.keys) + 1, UserId: userId, Prefix: prefix}
+	m.keys = append(m.keys, k)
+	return k, nil
+}
+
+func TestAuth_CreateAndAuthenticate(t *testing.T) {
+	repo := &mockUserRepo{}
+	svc := NewAuthService(repo)
+
+	rawKey, err := svc.CreateApiKey(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		t.Errorf("expected key prefix %q, got %q", apiKeyPrefix, rawKey)
+	}
+	if _, stored := repo.keyOwner[rawKey]; stored {
+		t.Error("raw key must not be stored")
+	}
+	if repo.keys[0].Prefix != rawKey[:apiKeyShownPrefix] {
+		t.Errorf("unexpected stored prefix: %s", repo.keys[0].Prefix)
+	}
+
+	u, err := svc.Authenticate(context.Background(), rawKey)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if u.Id != 7 {
+		t.Errorf("expected user 7, got %d", u.Id)
+	}
+}
+
+func TestAuth_InvalidKey(t *testing.T) {
+	svc := NewAuthService(&mockUserRepo{})
+
+	for _, key := range []string{"", "usk_doesnotexist"} {
+		if _, err := svc.Authenticate(context.Background(), key); !errors.Is(err, user.ErrUnauthorized) {
+			t.Errorf("key %q: expected ErrUnauthorized, got: %v", key, err)
+		}
+	}
+}
+
+func TestAuth_CreateUserRejectsUnknownRole(t *testing.T) {
+	repo := &mockUserRepo{}
+	svc := NewAuthService(repo)
+
+	if _, err := svc.CreateUser(context.Background(), "alice", "owner"); !errors.Is(err, user.ErrInvalidRole) {
+		t.Errorf("expected ErrInvalidRole, got: %v", err)
+	}
+	u, err := svc.CreateUser(context.Background(), "alice", user.RoleViewer)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !u.HasRole(user.RoleViewer) {
+		t.Errorf("expected viewer role, got %v", u.Roles)
+	}
+}
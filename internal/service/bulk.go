@@ -0,0 +1,288 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/workspace"
+	"awesomeProject/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// MaxBulkItems bounds the items of one bulk request, keeping its transaction
+// and response reasonably sized.
+const MaxBulkItems = 10000
+
+// bulkAlias identifies an alias on its domain within a bulk request.
+type bulkAlias struct {
+	domainId int
+	alias    string
+}
+
+// SaveMany validates drafts like Save and stores the valid ones in batches.
+// Links created in bulk are never deduplicated. Generated aliases that
+// collide are regenerated for the next batch, up to maxAliasAttempts times.
+func (s *urlService) SaveMany(ctx context.Context, drafts []url.Draft) ([]url.ItemResult, error) {
+	if err := checkBatchSize(len(drafts)); err != nil {
+		return nil, err
+	}
+	ownerId, err := callerId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ws, _ := workspace.FromContext(ctx)
+	log := s.log.With(
+		slog.Int("items", len(drafts)),
+		slog.String("request_id", logger.RequestIDFromContext(ctx)),
+	)
+
+	results := make([]url.ItemResult, len(drafts))
+	links, pending, err := s.draftLinks(ctx, ws, ownerId, drafts, results)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make(map[bulkAlias]bool)
+	for attempt := 0; len(pending) > 0 && attempt < maxAliasAttempts; attempt++ {
+		var batch []url.Url
+		var batchIdx, retry []int
+		for _, i := range pending {
+			generated := drafts[i].Alias == ""
+			if generated {
+				alias, err := s.generateAlias(ctx, log, attempt)
+				if err != nil {
+					log.Error(
+						"failed to generate alias",
+						slog.String("err", err.Error()),
+					)
+					return nil, err
+				}
+				if s.policy.IsReserved(alias) {
+					retry = append(retry, i)
+					continue
+				}
+				links[i].Alias = alias
+			}
+
+			key := bulkAlias{links[i].DomainId, links[i].Alias}
+			if claimed[key] {
+				if generated {
+					aliasCollisions.Add(1)
+					retry = append(retry, i)
+				} else {
+					results[i].Err = url.ErrAliasTaken
+				}
+				continue
+			}
+			claimed[key] = true
+			batch = append(batch, links[i])
+			batchIdx = append(batchIdx, i)
+		}
+
+		if len(batch) > 0 {
+			saved, err := s.repo.SaveMany(ctx, batch)
+			if err != nil {
+				log.Error(
+					"failed to save urls",
+					slog.String("err", err.Error()),
+				)
+				return nil, err
+			}
+			for j, result := range saved {
+				i := batchIdx[j]
+				if errors.Is(result.Err, url.ErrAliasTaken) && drafts[i].Alias == "" {
+					aliasCollisions.Add(1)
+					retry = append(retry, i)
+					continue
+				}
+				results[i] = result
+			}
+		}
+
+		pending = retry
+	}
+
+	if len(pending) > 0 {
+		aliasSpaceExhausted.Add(1)
+		log.Error(
+			"alias space exhausted",
+			slog.Int("attempts", maxAliasAttempts),
+			slog.Int("links", len(pending)),
+		)
+		for _, i := range pending {
+			results[i].Err = url.ErrAliasSpaceExhausted
+		}
+	}
+
+	return results, nil
+}
+
+func (s *urlService) CheckMany(ctx context.Context, drafts []url.Draft) ([]url.ItemResult, error) {
+	if err := checkBatchSize(len(drafts)); err != nil {
+		return nil, err
+	}
+	ownerId, err := callerId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ws, _ := workspace.FromContext(ctx)
+
+	results := make([]url.ItemResult, len(drafts))
+	links, valid, err := s.draftLinks(ctx, ws, ownerId, drafts, results)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make(map[bulkAlias]bool)
+	var named []url.Url
+	var namedIdx []int
+	for _, i := range valid {
+		results[i].Url = links[i]
+		if links[i].Alias == "" {
+			continue
+		}
+		key := bulkAlias{links[i].DomainId, links[i].Alias}
+		if claimed[key] {
+			results[i] = url.ItemResult{Err: url.ErrAliasTaken}
+			continue
+		}
+		claimed[key] = true
+		named = append(named, links[i])
+		namedIdx = append(namedIdx, i)
+	}
+	if len(named) == 0 {
+		return results, nil
+	}
+
+	taken, err := s.repo.TakenAliases(ctx, named)
+	if err != nil {
+		s.log.Error(
+			"failed to check aliases",
+			slog.Int("items", len(named)),
+			slog.String("err", err.Error()),
+		)
+		return nil, err
+	}
+	for j, isTaken := range taken {
+		if isTaken {
+			results[namedIdx[j]] = url.ItemResult{Err: url.ErrAliasTaken}
+		}
+	}
+
+	return results, nil
+}
+
+// draftLinks validates drafts like Save, recording the failures in results.
+// It returns the links for the drafts, valid at the returned indexes.
+func (s *urlService) draftLinks(
+	ctx context.Context,
+	ws workspace.Workspace,
+	ownerId int,
+	drafts []url.Draft,
+	results []url.ItemResult,
+) ([]url.Url, []int, error) {
+	links := make([]url.Url, len(drafts))
+	domains := make(map[string]url.Domain)
+	var valid []int
+	for i, draft := range drafts {
+		d, ok := domains[draft.Host]
+		if !ok {
+			var err error
+			d, err = s.resolveDomain(ctx, ws, draft.Host)
+			if errors.Is(err, url.ErrUnknownDomain) {
+				results[i].Err = err
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			domains[draft.Host] = d
+		}
+
+		link, err := s.newLink(ctx, ws, ownerId, draft, d)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		links[i] = link
+		valid = append(valid, i)
+	}
+
+	return links, valid, nil
+}
+
+// UpdateMany validates changes like Update and applies the valid ones in one
+// transaction.
+func (s *urlService) UpdateMany(ctx context.Context, changes []url.Change) ([]url.ItemResult, error) {
+	if err := checkBatchSize(len(changes)); err != nil {
+		return nil, err
+	}
+	scope, err := writeScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]url.ItemResult, len(changes))
+	var valid []url.Change
+	var validIdx []int
+	for i, c := range changes {
+		c, err := s.normalizeChange(ctx, c)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, c)
+		validIdx = append(validIdx, i)
+	}
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	errs, err := s.repo.UpdateMany(ctx, scope, valid)
+	if err != nil {
+		s.log.Error(
+			"failed to update urls",
+			slog.Int("items", len(valid)),
+			slog.String("err", err.Error()),
+		)
+		return nil, err
+	}
+	for j, err := range errs {
+		results[validIdx[j]].Err = err
+	}
+
+	return results, nil
+}
+
+func (s *urlService) DeleteMany(ctx context.Context, ids []int) ([]url.ItemResult, error) {
+	if err := checkBatchSize(len(ids)); err != nil {
+		return nil, err
+	}
+	scope, err := writeScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	errs, err := s.repo.DeleteMany(ctx, scope, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]url.ItemResult, len(ids))
+	for i, err := range errs {
+		results[i].Err = err
+	}
+
+	return results, nil
+}
+
+func checkBatchSize(n int) error {
+	if n > MaxBulkItems {
+		return fmt.Errorf("%w: at most %d items are allowed", url.ErrBatchTooLarge, MaxBulkItems)
+	}
+	return nil
+}
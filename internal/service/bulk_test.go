@@ -0,0 +1,242 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// bulkRepo fakes the alias index of a url table for SaveMany.
+type bulkRepo struct {
+	taken   map[string]bool
+	batches int
+}
+
+func (r *bulkRepo) saveMany(ctx context.Context, links []url.Url) ([]url.ItemResult, error) {
+	r.batches++
+	results := make([]url.ItemResult, len(links))
+	for i, link := range links {
+		if r.taken[link.Alias] {
+			results[i].Err = url.ErrAliasTaken
+			continue
+		}
+		r.taken[link.Alias] = true
+		link.Id = len(r.taken)
+		results[i].Url = link
+	}
+	return results, nil
+}
+
+func TestSaveMany(t *testing.T) {
+	store := &bulkRepo{taken: map[string]bool{"taken": true, "gen1": true}}
+	repo := &mockRepo{saveManyFn: store.saveMany}
+	gen := &mockGenerator{aliases: []string{"gen1", "gen2", "gen3"}}
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, true)
+
+	drafts := []url.Draft{
+		{OriginalUrl: "https://example.com/a", Alias: "first"},
+		{OriginalUrl: "javascript:alert(1)", Alias: "bad"},
+		{OriginalUrl: "https://example.com/b", Alias: "taken"},
+		{OriginalUrl: "https://example.com/c", Alias: "first"},
+		{OriginalUrl: "https://example.com/d"},
+		{OriginalUrl: "https://example.com/e", Host: "nope.example.com"},
+		{OriginalUrl: "https://example.com/f", Details: url.Details{Tags: []string{""}}},
+		{OriginalUrl: "https://example.com/g"},
+	}
+	results, err := svc.SaveMany(userCtx(), drafts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(results) != len(drafts) {
+		t.Fatalf("expected %d results, got %d", len(drafts), len(results))
+	}
+
+	wantErrs := []error{
+		nil,
+		url.ErrInvalidUrl,
+		url.ErrAliasTaken,
+		url.ErrAliasTaken,
+		nil,
+		url.ErrUnknownDomain,
+		url.ErrInvalidDetails,
+		nil,
+	}
+	for i, want := range wantErrs {
+		if !errors.Is(results[i].Err, want) {
+			t.Errorf("item %d: expected %v, got: %v", i, want, results[i].Err)
+		}
+	}
+
+	// the first generated alias collides and is replaced in a second batch
+	if results[4].Url.Alias != "gen3" || results[7].Url.Alias != "gen2" {
+		t.Errorf("unexpected generated aliases: %q, %q", results[4].Url.Alias, results[7].Url.Alias)
+	}
+	if results[0].Url.OwnerId != testUserId {
+		t.Errorf("expected owner %d, got %d", testUserId, results[0].Url.OwnerId)
+	}
+	if store.batches != 2 {
+		t.Errorf("expected 2 batches, got %d", store.batches)
+	}
+}
+
+func TestSaveMany_AliasSpaceExhausted(t *testing.T) {
+	store := &bulkRepo{taken: map[string]bool{"dup": true}}
+	repo := &mockRepo{saveManyFn: store.saveMany}
+	aliases := make([]string, maxAliasAttempts)
+	for i := range aliases {
+		aliases[i] = "dup"
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{aliases: aliases}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	results, err := svc.SaveMany(userCtx(), []url.Draft{{OriginalUrl: "https://example.com"}})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !errors.Is(results[0].Err, url.ErrAliasSpaceExhausted) {
+		t.Errorf("expected ErrAliasSpaceExhausted, got: %v", results[0].Err)
+	}
+}
+
+func TestSaveMany_BlockAliasTakenByCustomAlias(t *testing.T) {
+	store := &bulkRepo{taken: map[string]bool{"blk1": true}}
+	repo := &mockRepo{saveManyFn: store.saveMany}
+	gen := NewBlockGenerator(&mockCounter{}, 10, func(id uint64) string { return "blk" + decimal(id) })
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	results, err := svc.SaveMany(userCtx(), []url.Draft{
+		{OriginalUrl: "https://example.com/a"},
+		{OriginalUrl: "https://example.com/b", Alias: "blk3"},
+		{OriginalUrl: "https://example.com/c"},
+		{OriginalUrl: "https://example.com/d"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// blk1 is held by a stored custom alias and blk3 by one in the batch:
+	// the generated items move on to the next ids instead of failing
+	wantAliases := []string{"blk5", "blk3", "blk2", "blk4"}
+	for i, want := range wantAliases {
+		if results[i].Err != nil {
+			t.Errorf("item %d: expected no error, got: %v", i, results[i].Err)
+			continue
+		}
+		if results[i].Url.Alias != want {
+			t.Errorf("item %d: expected alias %q, got %q", i, want, results[i].Url.Alias)
+		}
+	}
+}
+
+func TestCheckMany(t *testing.T) {
+	var checked []url.Url
+	repo := &mockRepo{
+		takenFn: func(ctx context.Context, links []url.Url) ([]bool, error) {
+			checked = links
+			taken := make([]bool, len(links))
+			for i, link := range links {
+				taken[i] = link.Alias == "taken"
+			}
+			return taken, nil
+		},
+	}
+	gen := &mockGenerator{}
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	drafts := []url.Draft{
+		{OriginalUrl: "HTTPS://Example.com/a", Alias: "first"},
+		{OriginalUrl: "https://example.com/b", Alias: "taken"},
+		{OriginalUrl: "https://example.com/c", Alias: "first"},
+		{OriginalUrl: "https://example.com/d"},
+		{OriginalUrl: "ftp://example.com"},
+	}
+	results, err := svc.CheckMany(userCtx(), drafts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	wantErrs := []error{nil, url.ErrAliasTaken, url.ErrAliasTaken, nil, url.ErrInvalidUrl}
+	for i, want := range wantErrs {
+		if !errors.Is(results[i].Err, want) {
+			t.Errorf("item %d: expected %v, got: %v", i, want, results[i].Err)
+		}
+	}
+	if results[0].Url.OriginalUrl != "https://example.com/a" {
+		t.Errorf("expected the normalized link, got %+v", results[0].Url)
+	}
+	if results[3].Url.Alias != "" || gen.index != 0 {
+		t.Errorf("expected no alias to be generated, got %q after %d calls", results[3].Url.Alias, gen.index)
+	}
+	if len(checked) != 2 {
+		t.Errorf("expected only the distinct explicit aliases to be looked up, got %+v", checked)
+	}
+}
+
+func TestUpdateMany(t *testing.T) {
+	var applied []url.Change
+	repo := &mockRepo{
+		updateManyFn: func(ctx context.Context, changes []url.Change) ([]error, error) {
+			applied = changes
+			return []error{nil, url.ErrNotFound}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	changes := []url.Change{
+		{Id: 1, NewUrl: "HTTPS://Example.com/a"},
+		{Id: 2, NewUrl: "https://example.com", ExpiresAt: time.Now().Add(-time.Hour)},
+		{Id: 3, NewUrl: "https://example.com", Alias: "new-alias"},
+	}
+	results, err := svc.UpdateMany(userCtx(), changes)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(applied) != 2 || applied[0].NewUrl != "https://example.com/a" || applied[1].Id != 3 {
+		t.Errorf("expected the valid changes normalized, got %+v", applied)
+	}
+	if repo.ownerId != testUserId {
+		t.Errorf("expected updates scoped to owner %d, got %d", testUserId, repo.ownerId)
+	}
+	wantErrs := []error{nil, url.ErrInvalidExpiry, url.ErrNotFound}
+	for i, want := range wantErrs {
+		if !errors.Is(results[i].Err, want) {
+			t.Errorf("item %d: expected %v, got: %v", i, want, results[i].Err)
+		}
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	repo := &mockRepo{
+		deleteManyFn: func(ctx context.Context, ids []int) ([]error, error) {
+			return []error{nil, url.ErrNotFound}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	results, err := svc.DeleteMany(userCtx(), []int{4, 5})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if results[0].Err != nil || !errors.Is(results[1].Err, url.ErrNotFound) {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if repo.ownerId != testUserId {
+		t.Errorf("expected deletes scoped to owner %d, got %d", testUserId, repo.ownerId)
+	}
+}
+
+func TestBulk_TooLarge(t *testing.T) {
+	svc := NewUrlService(&mockRepo{}, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	if _, err := svc.SaveMany(userCtx(), make([]url.Draft, MaxBulkItems+1)); !errors.Is(err, url.ErrBatchTooLarge) {
+		t.Errorf("SaveMany: expected ErrBatchTooLarge, got: %v", err)
+	}
+	if _, err := svc.UpdateMany(userCtx(), make([]url.Change, MaxBulkItems+1)); !errors.Is(err, url.ErrBatchTooLarge) {
+		t.Errorf("UpdateMany: expected ErrBatchTooLarge, got: %v", err)
+	}
+	if _, err := svc.DeleteMany(userCtx(), make([]int, MaxBulkItems+1)); !errors.Is(err, url.ErrBatchTooLarge) {
+		t.Errorf("DeleteMany: expected ErrBatchTooLarge, got: %v", err)
+	}
+}
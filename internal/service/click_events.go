@@ -0,0 +1,185 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/click"
+	"awesomeProject/internal/repositiries"
+	"context"
+	"log/slog"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ClickRecorder accepts click events from the redirect path. Implementations
+// must never block the caller.
+type ClickRecorder interface {
+	Record(e click.Event)
+}
+
+// ClickEventQueue buffers click events in a bounded channel and writes them to
+// the repository in batches from a single background worker. When the queue is
+// full new events are dropped rather than slowing down redirects.
+type ClickEventQueue struct {
+	repo      repositiries.ClickRepository
+	log       *slog.Logger
+	events    chan click.Event
+	batchSize int
+	interval  time.Duration
+	dropped   atomic.Int64
+}
+
+func NewClickEventQueue(
+	repo repositiries.ClickRepository,
+	logger *slog.Logger,
+	queueSize int,
+	batchSize int,
+	interval time.Duration,
+) *ClickEventQueue {
+	return &ClickEventQueue{
+		repo:      repo,
+		log:       logger,
+		events:    make(chan click.Event, queueSize),
+		batchSize: batchSize,
+		interval:  interval,
+	}
+}
+
+func (q *ClickEventQueue) Record(e click.Event) {
+	select {
+	case q.events <- e:
+	default:
+		q.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of events discarded because the queue was full.
+func (q *ClickEventQueue) Dropped() int64 {
+	return q.dropped.Load()
+}
+
+// Run consumes the queue until ctx is cancelled, then drains whatever is left
+// so buffered events are written before shutdown. With a non-positive
+// interval batches are only written once full and on shutdown.
+func (q *ClickEventQueue) Run(ctx context.Context) {
+	var tick <-chan time.Time
+	if q.interval > 0 {
+		ticker := time.NewTicker(q.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	batch := make([]click.Event, 0, q.batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case e := <-q.events:
+					batch = append(batch, e)
+					if len(batch) >= q.batchSize {
+						batch = q.flush(batch)
+					}
+				default:
+					q.flush(batch)
+					return
+				}
+			}
+		case e := <-q.events:
+			batch = append(batch, e)
+			if len(batch) >= q.batchSize {
+				batch = q.flush(batch)
+			}
+		case <-tick:
+			batch = q.flush(batch)
+			if n := q.dropped.Swap(0); n > 0 {
+				q.log.Warn("click events dropped, queue full", slog.Int64("count", n))
+			}
+		}
+	}
+}
+
+func (q *ClickEventQueue) flush(batch []click.Event) []click.Event {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.repo.SaveBatch(ctx, batch); err != nil {
+		q.log.Error(
+			"failed to save click events",
+			slog.Int("count", len(batch)),
+			slog.String("err", err.Error()),
+		)
+	}
+
+	return batch[:0]
+}
+
+// NewClickEvent builds an anonymized click event from raw request data.
+func NewClickEvent(urlId int, referrer, userAgent, ip, requestId string, at time.Time) click.Event {
+	return click.Event{
+		UrlId:           urlId,
+		ClickedAt:       at,
+		Referrer:        referrer,
+		UserAgentFamily: UserAgentFamily(userAgent),
+		IpPrefix:        AnonymizeIP(ip),
+		RequestId:       requestId,
+	}
+}
+
+// AnonymizeIP truncates an address to its /24 network (IPv4) or /48 network
+// (IPv6). Unparseable input yields an empty string.
+func AnonymizeIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+
+	return prefix.String()
+}
+
+// userAgentFamilies is checked in order; more specific tokens must come first
+// because e.g. Edge and Opera user agents also contain "Chrome".
+var userAgentFamilies = []struct {
+	token  string
+	family string
+}{
+	{"bot", "Bot"},
+	{"spider", "Bot"},
+	{"crawl", "Bot"},
+	{"curl/", "curl"},
+	{"wget/", "Wget"},
+	{"edg/", "Edge"},
+	{"opr/", "Opera"},
+	{"firefox/", "Firefox"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+}
+
+// UserAgentFamily classifies a User-Agent header into a coarse browser family.
+func UserAgentFamily(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+
+	ua := strings.ToLower(userAgent)
+	for _, f := range userAgentFamilies {
+		if strings.Contains(ua, f.token) {
+			return f.family
+		}
+	}
+
+	return "Other"
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/click"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockClickRepo struct {
+	mu      sync.Mutex
+	events  []click.Event
+	statsFn func(ctx context.Context, urlId int, from, to time.Time, interval string) (click.Stats, error)
+}
+
+func (m *mockClickRepo) SaveBatch(ctx context.Context, events []click.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *mockClickRepo) Stats(
+	ctx context.Context,
+	urlId int,
+	from, to time.Time,
+	interval string,
+) (click.Stats, error) {
+	return m.statsFn(ctx, urlId, from, to, interval)
+}
+
+func TestClickEventQueue_RecordDropsWhenFull(t *testing.T) {
+	q := NewClickEventQueue(&mockClickRepo{}, newLogger(), 2, 10, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		q.Record(click.Event{UrlId: i})
+	}
+
+	if q.Dropped() != 3 {
+		t.Errorf("expected 3 dropped events, got: %d", q.Dropped())
+	}
+}
+
+func TestClickEventQueue_RunDrainsOnShutdown(t *testing.T) {
+	repo := &mockClickRepo{}
+	q := NewClickEventQueue(repo, newLogger(), 100, 10, time.Hour)
+
+	for i := 0; i < 25; i++ {
+		q.Record(click.Event{UrlId: i})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q.Run(ctx)
+
+	if len(repo.events) != 25 {
+		t.Errorf("expected 25 saved events, got: %d", len(repo.events))
+	}
+}
+
+func TestClickEventQueue_RunWithoutInterval(t *testing.T) {
+	repo := &mockClickRepo{}
+	q := NewClickEventQueue(repo, newLogger(), 100, 10, 0)
+
+	for i := 0; i < 5; i++ {
+		q.Record(click.Event{UrlId: i})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q.Run(ctx)
+
+	if len(repo.events) != 5 {
+		t.Errorf("expected 5 saved events, got: %d", len(repo.events))
+	}
+}
+
+func TestAnonymizeIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"192.168.10.77", "192.168.10.0/24"},
+		{"::ffff:10.1.2.3", "10.1.2.0/24"},
+		{"2001:db8:abcd:12::1", "2001:db8:abcd::/48"},
+		{"not-an-ip", ""},
+		{"", ""},
+	}
+
+	for _, tc := range tests {
+		got := AnonymizeIP(tc.ip)
+		if got != tc.want {
+			t.Errorf("AnonymizeIP(%q) = %q, want %q", tc.ip, got, tc.want)
+		}
+	}
+}
+
+func TestUserAgentFamily(t *testing.T) {
+	tests := []struct {
+		ua   string
+		want string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", "Chrome"},
+		{"Mozilla/5.0 (Windows NT 10.0) AppleWebKit/537.36 Chrome/120.0 Safari/537.36 Edg/120.0", "Edge"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "Firefox"},
+		{"Mozilla/5.0 (Macintosh) AppleWebKit/605.1.15 Version/17.0 Safari/605.1.15", "Safari"},
+		{"Googlebot/2.1 (+http://www.google.com/bot.html)", "Bot"},
+		{"curl/8.4.0", "curl"},
+		{"SomethingElse/1.0", "Other"},
+		{"", ""},
+	}
+
+	for _, tc := range tests {
+		got := UserAgentFamily(tc.ua)
+		if got != tc.want {
+			t.Errorf("UserAgentFamily(%q) = %q, want %q", tc.ua, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"awesomeProject/internal/repositiries"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ClickCounter records redirects without touching the database on the hot path.
+type ClickCounter interface {
+	Add(id int)
+}
+
+// ClickAggregator buffers click counts in memory and periodically flushes them
+// to the repository as batched increments.
+type ClickAggregator struct {
+	repo     repositiries.UrlRepository
+	log      *slog.Logger
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[int]int64
+}
+
+func NewClickAggregator(
+	repo repositiries.UrlRepository,
+	logger *slog.Logger,
+	interval time.Duration,
+) *ClickAggregator {
+	return &ClickAggregator{
+		repo:     repo,
+		log:      logger,
+		interval: interval,
+		pending:  make(map[int]int64),
+	}
+}
+
+func (a *ClickAggregator) Add(id int) {
+	a.mu.Lock()
+	a.pending[id]++
+	a.mu.Unlock()
+}
+
+// Flush writes all buffered counts. On failure the counts are merged back so
+// they are retried on the next flush.
+func (a *ClickAggregator) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	batch := a.pending
+	a.pending = make(map[int]int64)
+	a.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := a.repo.IncrementClicks(ctx, batch); err != nil {
+		a.mu.Lock()
+		for id, n := range batch {
+			a.pending[id] += n
+		}
+		a.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// Run flushes every interval until ctx is cancelled, then performs a final
+// flush so buffered clicks are not lost on shutdown. With a non-positive
+// interval clicks are only flushed on shutdown.
+func (a *ClickAggregator) Run(ctx context.Context) {
+	var tick <-chan time.Time
+	if a.interval > 0 {
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			a.flush(flushCtx)
+			cancel()
+			return
+		case <-tick:
+			a.flush(ctx)
+		}
+	}
+}
+
+func (a *ClickAggregator) flush(ctx context.Context) {
+	if err := a.Flush(ctx); err != nil {
+		a.log.Error("failed to flush clicks", slog.String("err", err.Error()))
+	}
+}
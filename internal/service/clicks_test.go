@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClickAggregator_FlushBatchesCounts(t *testing.T) {
+	var flushed map[int]int64
+	repo := &mockRepo{
+		incClicksFn: func(ctx context.Context, clicks map[int]int64) error {
+			flushed = clicks
+			return nil
+		},
+	}
+	agg := NewClickAggregator(repo, newLogger(), 0)
+
+	agg.Add(1)
+	agg.Add(1)
+	agg.Add(2)
+
+	if err := agg.Flush(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if flushed[1] != 2 || flushed[2] != 1 {
+		t.Errorf("unexpected flushed counts: %v", flushed)
+	}
+}
+
+func TestClickAggregator_FlushEmptyIsNoop(t *testing.T) {
+	repo := &mockRepo{
+		incClicksFn: func(ctx context.Context, clicks map[int]int64) error {
+			t.Error("repo should not be called")
+			return nil
+		},
+	}
+	agg := NewClickAggregator(repo, newLogger(), 0)
+
+	if err := agg.Flush(context.Background()); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestClickAggregator_FlushErrorRetainsCounts(t *testing.T) {
+	repoErr := errors.New("db error")
+	calls := 0
+	var flushed map[int]int64
+	repo := &mockRepo{
+		incClicksFn: func(ctx context.Context, clicks map[int]int64) error {
+			calls++
+			if calls == 1 {
+				return repoErr
+			}
+			flushed = clicks
+			return nil
+		},
+	}
+	agg := NewClickAggregator(repo, newLogger(), 0)
+
+	agg.Add(7)
+	if err := agg.Flush(context.Background()); !errors.Is(err, repoErr) {
+		t.Fatalf("expected db error, got: %v", err)
+	}
+
+	agg.Add(7)
+	if err := agg.Flush(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if flushed[7] != 2 {
+		t.Errorf("expected 2 clicks after retry, got: %d", flushed[7])
+	}
+}
+
+func TestClickAggregator_RunFlushesOnShutdown(t *testing.T) {
+	var mu sync.Mutex
+	var total int64
+	repo := &mockRepo{
+		incClicksFn: func(ctx context.Context, clicks map[int]int64) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, n := range clicks {
+				total += n
+			}
+			return nil
+		},
+	}
+	agg := NewClickAggregator(repo, newLogger(), time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		agg.Run(ctx)
+	}()
+
+	for i := 0; i < 100; i++ {
+		agg.Add(1)
+	}
+	cancel()
+	<-done
+
+	if total != 100 {
+		t.Errorf("expected 100 clicks flushed on shutdown, got: %d", total)
+	}
+}
+
+func TestClickAggregator_RunWithoutInterval(t *testing.T) {
+	var flushed map[int]int64
+	repo := &mockRepo{
+		incClicksFn: func(ctx context.Context, clicks map[int]int64) error {
+			flushed = clicks
+			return nil
+		},
+	}
+	agg := NewClickAggregator(repo, newLogger(), 0)
+	agg.Add(3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	agg.Run(ctx)
+
+	if flushed[3] != 1 {
+		t.Errorf("expected the click flushed on shutdown, got: %v", flushed)
+	}
+}
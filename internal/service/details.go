@@ -0,0 +1,89 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxTags              = 32
+	maxTagLength         = 64
+	maxTitleLength       = 256
+	maxDescriptionLength = 4096
+)
+
+// normalizeDetails validates d and returns it with its tags normalized.
+func normalizeDetails(d url.Details) (url.Details, error) {
+	var err error
+	if d.Title, err = checkText("title", d.Title, maxTitleLength); err != nil {
+		return url.Details{}, err
+	}
+	if d.Description, err = checkText("description", d.Description, maxDescriptionLength); err != nil {
+		return url.Details{}, err
+	}
+	if d.Tags, err = normalizeTags(d.Tags); err != nil {
+		return url.Details{}, err
+	}
+	return d, nil
+}
+
+func normalizeDetailsPatch(p url.DetailsPatch) (url.DetailsPatch, error) {
+	if p.Title != nil {
+		title, err := checkText("title", *p.Title, maxTitleLength)
+		if err != nil {
+			return url.DetailsPatch{}, err
+		}
+		p.Title = &title
+	}
+	if p.Description != nil {
+		description, err := checkText("description", *p.Description, maxDescriptionLength)
+		if err != nil {
+			return url.DetailsPatch{}, err
+		}
+		p.Description = &description
+	}
+	if p.Tags != nil {
+		tags, err := normalizeTags(*p.Tags)
+		if err != nil {
+			return url.DetailsPatch{}, err
+		}
+		p.Tags = &tags
+	}
+	return p, nil
+}
+
+func checkText(field, s string, maxLength int) (string, error) {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) > maxLength {
+		return "", fmt.Errorf("%w: %s is longer than %d characters", url.ErrInvalidDetails, field, maxLength)
+	}
+	return s, nil
+}
+
+// normalizeTags trims and lower cases tags and drops duplicates, keeping the
+// order they were given in.
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) > maxTags {
+		return nil, fmt.Errorf("%w: at most %d tags are allowed", url.ErrInvalidDetails, maxTags)
+	}
+
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, fmt.Errorf("%w: tags must not be empty", url.ErrInvalidDetails)
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", url.ErrInvalidDetails, tag, maxTagLength)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := normalizeTags([]string{" Summer-Sale ", "newsletter", "summer-sale", "NEWSLETTER"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if want := []string{"summer-sale", "newsletter"}; !slices.Equal(got, want) {
+		t.Errorf("normalizeTags = %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeDetails_Rejected(t *testing.T) {
+	tooManyTags := make([]string, maxTags+1)
+	for i := range tooManyTags {
+		tooManyTags[i] = strings.Repeat("t", i+1)
+	}
+
+	tests := []struct {
+		name    string
+		details url.Details
+	}{
+		{"empty tag", url.Details{Tags: []string{"ok", "  "}}},
+		{"long tag", url.Details{Tags: []string{strings.Repeat("t", maxTagLength+1)}}},
+		{"too many tags", url.Details{Tags: tooManyTags}},
+		{"long title", url.Details{Title: strings.Repeat("т", maxTitleLength+1)}},
+		{"long description", url.Details{Description: strings.Repeat("d", maxDescriptionLength+1)}},
+	}
+
+	for _, tt := range tests {
+		if _, err := normalizeDetails(tt.details); !errors.Is(err, url.ErrInvalidDetails) {
+			t.Errorf("%s: expected ErrInvalidDetails, got: %v", tt.name, err)
+		}
+	}
+}
+
+func TestSave_RecordsDetails(t *testing.T) {
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			if urlHash != "" {
+				t.Errorf("links with details must not be deduplicated, got hash %q", urlHash)
+			}
+			return url.Url{Alias: alias}, nil
+		},
+		getByHashFn: func(ctx context.Context, urlHash string) (url.Url, error) {
+			t.Fatal("links with details must not be deduplicated")
+			return url.Url{}, nil
+		},
+	}
+	gen := &mockGenerator{aliases: []string{"abc123"}}
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, true)
+
+	details := url.Details{
+		Title:    " May newsletter ",
+		Tags:     []string{"Newsletter", "may"},
+		Metadata: map[string]any{"campaign": "2026-05"},
+	}
+	if _, err := svc.Save(userCtx(), "", "https://example.com", "", time.Time{}, details); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	saved := repo.saved.Details
+	if saved.Title != "May newsletter" || !slices.Equal(saved.Tags, []string{"newsletter", "may"}) {
+		t.Errorf("unexpected saved details: %+v", saved)
+	}
+	if saved.Metadata["campaign"] != "2026-05" {
+		t.Errorf("expected metadata to be kept, got %v", saved.Metadata)
+	}
+}
+
+func TestUpdate_NormalizesDetailsPatch(t *testing.T) {
+	repo := &mockRepo{
+		updateFn: func(ctx context.Context, id int, newUrl, alias string, expiresAt time.Time) error {
+			return nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	title := "  Spring sale "
+	tags := []string{"Sale", "sale"}
+	patch := url.DetailsPatch{Title: &title, Tags: &tags}
+	if err := svc.Update(userCtx(), 1, "https://example.com", "", time.Time{}, patch); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if repo.patch.Title == nil || *repo.patch.Title != "Spring sale" {
+		t.Errorf("expected trimmed title, got %v", repo.patch.Title)
+	}
+	if repo.patch.Tags == nil || !slices.Equal(*repo.patch.Tags, []string{"sale"}) {
+		t.Errorf("expected normalized tags, got %v", repo.patch.Tags)
+	}
+	if repo.patch.Description != nil || repo.patch.Metadata != nil {
+		t.Errorf("expected untouched fields to stay nil, got %+v", repo.patch)
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/workspace"
+	"awesomeProject/internal/repositiries"
+	"context"
+	"fmt"
+	"strings"
+)
+
+type DomainService interface {
+	// Create registers host for short links. A non-empty workspaceName
+	// restricts the domain to that workspace's links.
+	Create(ctx context.Context, host, workspaceName string) (url.Domain, error)
+	// List returns the domains the caller may create links on, or every
+	// domain for admins.
+	List(ctx context.Context) ([]url.Domain, error)
+}
+
+type domainService struct {
+	repo       repositiries.DomainRepository
+	workspaces repositiries.WorkspaceRepository
+}
+
+func NewDomainService(repo repositiries.DomainRepository, workspaces repositiries.WorkspaceRepository) DomainService {
+	return &domainService{repo: repo, workspaces: workspaces}
+}
+
+func (s *domainService) Create(ctx context.Context, host, workspaceName string) (url.Domain, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return url.Domain{}, err
+	}
+
+	var workspaceId int
+	if workspaceName != "" {
+		w, err := s.workspaces.GetByName(ctx, workspaceName)
+		if err != nil {
+			return url.Domain{}, err
+		}
+		workspaceId = w.Id
+	}
+
+	return s.repo.Create(ctx, host, workspaceId)
+}
+
+func (s *domainService) List(ctx context.Context) ([]url.Domain, error) {
+	if _, err := callerId(ctx); err != nil {
+		return nil, err
+	}
+
+	domains, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin(ctx) {
+		return domains, nil
+	}
+
+	ws, _ := workspace.FromContext(ctx)
+	usable := domains[:0]
+	for _, d := range domains {
+		if d.UsableIn(ws.Id) {
+			usable = append(usable, d)
+		}
+	}
+
+	return usable, nil
+}
+
+// normalizeDomain validates a host name to register and returns it in the
+// form hosts are looked up in.
+func normalizeDomain(host string) (string, error) {
+	normalized := NormalizeHost(host)
+	if !strings.Contains(normalized, ".") || strings.ContainsAny(normalized, "/:@ ") {
+		return "", fmt.Errorf("%w: %q is not a host name", url.ErrInvalidDomain, host)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/domain/workspace"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDomain_Create(t *testing.T) {
+	domains := &mockDomainRepo{}
+	workspaces := &mockWorkspaceRepo{workspaces: []workspace.Workspace{{Id: 7, Name: "docs"}}}
+	svc := NewDomainService(domains, workspaces)
+
+	d, err := svc.Create(adminCtx(), "Go.Example.com.", "docs")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if d.Host != "go.example.com" || d.WorkspaceId != 7 {
+		t.Errorf("unexpected domain: %+v", d)
+	}
+
+	if _, err := svc.Create(adminCtx(), "go.example.com", ""); !errors.Is(err, url.ErrDomainTaken) {
+		t.Errorf("expected ErrDomainTaken, got: %v", err)
+	}
+	if _, err := svc.Create(adminCtx(), "links.example.com", "missing"); !errors.Is(err, workspace.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestDomain_CreateInvalid(t *testing.T) {
+	svc := NewDomainService(&mockDomainRepo{}, &mockWorkspaceRepo{})
+
+	for _, host := range []string{"", "localhost", "https://go.example.com/", "user@go.example.com"} {
+		if _, err := svc.Create(adminCtx(), host, ""); !errors.Is(err, url.ErrInvalidDomain) {
+			t.Errorf("Create(%q): expected ErrInvalidDomain, got: %v", host, err)
+		}
+	}
+}
+
+func TestDomain_ListFiltersByWorkspace(t *testing.T) {
+	all := []url.Domain{
+		{Id: 1, Host: "links.example.com"},
+		{Id: 2, Host: "go.example.com", WorkspaceId: 7},
+		{Id: 3, Host: "ops.example.com", WorkspaceId: 8},
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantIds []int
+	}{
+		{"personal", userCtx(), []int{1}},
+		{"in workspace", workspaceCtx(workspace.Workspace{Id: 7}), []int{1, 2}},
+		{"admin", adminCtx(), []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domains := &mockDomainRepo{domains: append([]url.Domain(nil), all...)}
+			svc := NewDomainService(domains, &mockWorkspaceRepo{})
+
+			got, err := svc.List(tt.ctx)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(got) != len(tt.wantIds) {
+				t.Fatalf("expected %d domains, got %+v", len(tt.wantIds), got)
+			}
+			for i, d := range got {
+				if d.Id != tt.wantIds[i] {
+					t.Errorf("expected domain %d at %d, got %d", tt.wantIds[i], i, d.Id)
+				}
+			}
+		})
+	}
+}
+
+func TestDomainAccessPolicy(t *testing.T) {
+	svc := NewDomainAccessPolicy(NewDomainService(&mockDomainRepo{}, &mockWorkspaceRepo{}))
+	ctx := user.WithUser(context.Background(), user.User{Id: 1, Roles: []string{user.RoleEditor}})
+
+	if _, err := svc.Create(ctx, "go.example.com", ""); !errors.Is(err, user.ErrForbidden) {
+		t.Errorf("Create: expected ErrForbidden, got: %v", err)
+	}
+	if _, err := svc.List(ctx); err != nil {
+		t.Errorf("List: expected no error, got: %v", err)
+	}
+}
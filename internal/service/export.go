@@ -0,0 +1,39 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/pkg/logger"
+	"context"
+	"log/slog"
+)
+
+func (s *urlService) Export(ctx context.Context, f url.ListFilter, fn func(url.Url) error) error {
+	scope, err := linkScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	f, err = normalizeListFilter(f)
+	if err != nil {
+		return err
+	}
+	f.AliasPrefix = s.policy.Normalize(f.AliasPrefix)
+	f.Now = s.now()
+
+	exported := 0
+	err = s.repo.Export(ctx, scope, f, func(u url.Url) error {
+		exported++
+		return fn(u)
+	})
+	if err != nil {
+		s.log.Error(
+			"failed to export urls",
+			slog.Int("exported", exported),
+			slog.String("request_id", logger.RequestIDFromContext(ctx)),
+			slog.String("err", err.Error()),
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	links := []url.Url{{Id: 1, Alias: "a"}, {Id: 2, Alias: "b"}, {Id: 3, Alias: "c"}}
+	repo := &mockRepo{
+		exportFn: func(ctx context.Context, fn func(url.Url) error) error {
+			for _, u := range links {
+				if err := fn(u); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	var got []int
+	err := svc.Export(userCtx(), url.ListFilter{Tags: []string{"Sale"}}, func(u url.Url) error {
+		got = append(got, u.Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(got) != len(links) {
+		t.Errorf("expected %d links, got %v", len(links), got)
+	}
+	if repo.ownerId != testUserId {
+		t.Errorf("expected export scoped to owner %d, got %d", testUserId, repo.ownerId)
+	}
+	if tags := repo.listQuery.Tags; len(tags) != 1 || tags[0] != "sale" {
+		t.Errorf("expected normalized tags, got %q", tags)
+	}
+
+	// a failing consumer stops the export
+	stop := errors.New("client gone")
+	calls := 0
+	err = svc.Export(userCtx(), url.ListFilter{}, func(u url.Url) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("expected the export to stop after the first link, got %d calls, err: %v", calls, err)
+	}
+}
+
+func TestExport_InvalidFilter(t *testing.T) {
+	svc := NewUrlService(&mockRepo{}, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	err := svc.Export(userCtx(), url.ListFilter{State: "archived"}, func(u url.Url) error { return nil })
+	if !errors.Is(err, url.ErrInvalidFilter) {
+		t.Errorf("expected ErrInvalidFilter, got: %v", err)
+	}
+}
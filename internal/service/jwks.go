@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// jwksMinRefetch limits how often a token naming an unknown key can force the
+// key set to be reloaded.
+const jwksMinRefetch = time.Minute
+
+// After a failed reload the next attempt waits jwksRetryMin, doubling with
+// every further failure up to jwksRetryMax.
+const (
+	jwksRetryMin = 5 * time.Second
+	jwksRetryMax = 5 * time.Minute
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeySource looks up token verification keys by key id.
+type KeySource interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// Jwks is a JSON Web Key Set read from a local file or an http(s) URL. The set
+// is reloaded once refresh has elapsed (0 disables periodic reloads) and when a
+// token names a key it does not know yet, so signing key rotation needs no
+// restart. Reloads happen outside the lock and at most one at a time; while a
+// reload is due or failing the last good set keeps being served.
+type Jwks struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+	now     func() time.Time
+	loads   singleflight.Group
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	// failures counts reloads failed in a row, retryAt is when the next one
+	// may start and loadErr is why the last one failed.
+	failures int
+	retryAt  time.Time
+	loadErr  error
+}
+
+// NewJwks loads the key set from source, which is a file path or an http(s)
+// URL.
+func NewJwks(ctx context.Context, source string, refresh time.Duration) (*Jwks, error) {
+	j := &Jwks{
+		source:  source,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		now:     time.Now,
+	}
+	keys, err := j.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	j.keys = keys
+	j.fetchedAt = j.now()
+
+	return j, nil
+}
+
+func (j *Jwks) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.Lock()
+	key, ok := j.lookup(kid)
+	now := j.now()
+	age := now.Sub(j.fetchedAt)
+	due := j.refresh > 0 && age >= j.refresh
+	missing := !ok && age >= jwksMinRefetch
+	var load <-chan singleflight.Result
+	if (due || missing) && !now.Before(j.retryAt) {
+		load = j.reload(ctx)
+	}
+	j.mu.Unlock()
+
+	// a due refresh completes in the background, the cached key is still good
+	if ok {
+		return key, nil
+	}
+	if load == nil {
+		return nil, ErrUnknownKey
+	}
+
+	select {
+	case <-load:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	key, ok = j.lookup(kid)
+	if !ok {
+		if j.loadErr != nil {
+			return nil, fmt.Errorf("%w (reload failed: %v)", ErrUnknownKey, j.loadErr)
+		}
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// reload fetches the key set unless a fetch is already running, in which case
+// the caller shares its result. The fetch outlives a canceled request so that
+// every waiter gets the new set. It must be called with j.mu held.
+func (j *Jwks) reload(ctx context.Context) <-chan singleflight.Result {
+	ctx = context.WithoutCancel(ctx)
+	return j.loads.DoChan("", func() (any, error) {
+		keys, err := j.fetch(ctx)
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		now := j.now()
+		if err != nil {
+			j.failures++
+			j.retryAt = now.Add(jwksBackoff(j.failures))
+			j.loadErr = err
+			return nil, err
+		}
+		j.keys = keys
+		j.fetchedAt = now
+		j.failures = 0
+		j.retryAt = time.Time{}
+		j.loadErr = nil
+		return nil, nil
+	})
+}
+
+// jwksBackoff is the delay before the next reload after failures failed ones.
+func jwksBackoff(failures int) time.Duration {
+	d := jwksRetryMin
+	for i := 1; i < failures && d < jwksRetryMax; i++ {
+		d *= 2
+	}
+	return min(d, jwksRetryMax)
+}
+
+// lookup finds a key by id. Tokens without a kid are accepted only when the
+// set holds a single key.
+func (j *Jwks) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+func (j *Jwks) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	raw, err := j.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read jwks: %w", err)
+	}
+
+	return ParseJwks(raw)
+}
+
+func (j *Jwks) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, 1<<20))
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJwks decodes the RSA and P-256 signing keys of a JWKS document. Keys of
+// other types or meant for encryption are skipped.
+func ParseJwks(raw []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var key crypto.PublicKey
+		var err error
+		switch {
+		case k.Kty == "RSA":
+			key, err = k.rsaKey()
+		case k.Kty == "EC" && k.Crv == "P-256":
+			key, err = k.ecKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks contains no usable signing keys")
+	}
+
+	return keys, nil
+}
+
+func (k jwk) rsaKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exp := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid rsa parameters")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
+
+func (k jwk) ecKey() (*ecdsa.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("invalid P-256 coordinates")
+	}
+
+	point := append(append([]byte{4}, x...), y...)
+	return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+}
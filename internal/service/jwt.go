@@ -0,0 +1,254 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/repositiries"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+type JwtConfig struct {
+	// Issuer and Audience are checked against the iss and aud claims when
+	// set.
+	Issuer   string
+	Audience string
+	// OwnerClaim names the claim identifying the caller. Together with the
+	// iss claim it identifies the local user; it is never matched against
+	// user names.
+	OwnerClaim string
+	// RolesClaim names the claim holding the caller's roles, either a string
+	// array or a space separated string.
+	RolesClaim string
+	// Leeway tolerates clock skew when checking exp and nbf.
+	Leeway time.Duration
+	// CreateUsers provisions a local user for a subject seen for the first
+	// time; otherwise such tokens are rejected.
+	CreateUsers bool
+}
+
+type jwtAuthenticator struct {
+	keys  KeySource
+	users repositiries.UserRepository
+	cfg   JwtConfig
+	now   func() time.Time
+}
+
+// NewJwtAuthenticator returns an Authenticator accepting RS256 and ES256
+// signed JWTs. Callers are mapped to local users by issuer and owner claim;
+// with cfg.CreateUsers users seen for the first time are created so their
+// links have an owner.
+func NewJwtAuthenticator(keys KeySource, users repositiries.UserRepository, cfg JwtConfig) Authenticator {
+	if cfg.OwnerClaim == "" {
+		cfg.OwnerClaim = "sub"
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+
+	return &jwtAuthenticator{keys: keys, users: users, cfg: cfg, now: time.Now}
+}
+
+func (a *jwtAuthenticator) Authenticate(ctx context.Context, token string) (user.User, error) {
+	claims, err := a.verify(ctx, token)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	owner, _ := claims[a.cfg.OwnerClaim].(string)
+	if owner == "" {
+		return user.User{}, fmt.Errorf("%w: missing %s claim", user.ErrUnauthorized, a.cfg.OwnerClaim)
+	}
+
+	issuer, _ := claims["iss"].(string)
+	u, err := a.resolveUser(ctx, issuer, owner)
+	if err != nil {
+		return user.User{}, err
+	}
+	u.Roles = claimStrings(claims[a.cfg.RolesClaim])
+
+	return u, nil
+}
+
+// resolveUser finds the local user for a token issuer and subject, creating
+// it on first use when allowed. A concurrent first request may win the
+// insert, hence the second lookup.
+func (a *jwtAuthenticator) resolveUser(ctx context.Context, issuer, subject string) (user.User, error) {
+	u, err := a.users.GetByIdentity(ctx, issuer, subject)
+	if !errors.Is(err, user.ErrNotFound) {
+		return u, err
+	}
+	if !a.cfg.CreateUsers {
+		return user.User{}, fmt.Errorf("%w: unknown subject", user.ErrUnauthorized)
+	}
+
+	// the stored role only applies to API keys, token roles always win
+	u, err = a.users.CreateWithIdentity(ctx, identityName(issuer, subject), user.RoleViewer, issuer, subject)
+	if errors.Is(err, user.ErrIdentityTaken) {
+		return a.users.GetByIdentity(ctx, issuer, subject)
+	}
+
+	return u, err
+}
+
+// identityName is the user name given to a token subject when it is
+// provisioned. It is qualified by the issuer to stay apart from the names of
+// API key users, which workspaces use to add members.
+func identityName(issuer, subject string) string {
+	return issuer + "#" + subject
+}
+
+// verify checks the token signature and registered claims and returns the
+// decoded claim set.
+func (a *jwtAuthenticator) verify(ctx context.Context, token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", user.ErrUnauthorized)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", user.ErrUnauthorized)
+	}
+	if header.Alg != AlgRS256 && header.Alg != AlgES256 {
+		return nil, fmt.Errorf("%w: unsupported alg %q", user.ErrUnauthorized, header.Alg)
+	}
+
+	key, err := a.keys.Key(ctx, header.Kid)
+	if err != nil {
+		if errors.Is(err, ErrUnknownKey) {
+			return nil, fmt.Errorf("%w: %w", user.ErrUnauthorized, err)
+		}
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", user.ErrUnauthorized)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(header.Alg, key, digest[:], sig) {
+		return nil, fmt.Errorf("%w: invalid signature", user.ErrUnauthorized)
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", user.ErrUnauthorized)
+	}
+	if err := a.validateClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", user.ErrUnauthorized, err)
+	}
+
+	return claims, nil
+}
+
+func (a *jwtAuthenticator) validateClaims(claims map[string]any) error {
+	now := a.now()
+
+	exp, ok := claimTime(claims["exp"])
+	if !ok {
+		return errors.New("missing exp claim")
+	}
+	if !now.Before(exp.Add(a.cfg.Leeway)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := claimTime(claims["nbf"]); ok && now.Add(a.cfg.Leeway).Before(nbf) {
+		return errors.New("token not yet valid")
+	}
+
+	if a.cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != a.cfg.Issuer {
+			return errors.New("unexpected issuer")
+		}
+	}
+	if a.cfg.Audience != "" && !slices.Contains(claimStrings(claims["aud"]), a.cfg.Audience) {
+		return errors.New("unexpected audience")
+	}
+
+	return nil
+}
+
+// verifySignature checks sig against the key type the alg demands, so an RSA
+// key can never be used to accept an ES256 token or vice versa.
+func verifySignature(alg string, key crypto.PublicKey, digest, sig []byte) bool {
+	switch alg {
+	case AlgRS256:
+		pub, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, sig) == nil
+	case AlgES256:
+		// JWS encodes ECDSA signatures as fixed size r || s, not ASN.1
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, digest, r, s)
+	default:
+		return false
+	}
+}
+
+func decodeSegment(segment string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// claimTime reads a NumericDate claim.
+func claimTime(v any) (time.Time, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*float64(time.Second))), true
+}
+
+// claimStrings reads a claim that is either a string array or a single
+// (space separated) string.
+func claimStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
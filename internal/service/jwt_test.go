@@ -0,0 +1,431 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/user"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var b64 = base64.RawURLEncoding
+
+type testKeys struct {
+	rsa  *rsa.PrivateKey
+	ec   *ecdsa.PrivateKey
+	jwks []byte
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point, err := ecKey.PublicKey.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{
+			"kty": "RSA", "kid": "rsa-1", "use": "sig",
+			"n": b64.EncodeToString(rsaKey.N.Bytes()),
+			"e": b64.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		},
+		{
+			"kty": "EC", "kid": "ec-1", "crv": "P-256",
+			"x": b64.EncodeToString(point[1:33]),
+			"y": b64.EncodeToString(point[33:]),
+		},
+		// encryption keys must be ignored
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return testKeys{rsa: rsaKey, ec: ecKey, jwks: jwks}
+}
+
+func (k testKeys) writeJwks(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, k.jwks, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func (k testKeys) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var sig []byte
+	var err error
+	switch alg {
+	case AlgRS256:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+	case AlgES256:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k.ec, digest[:])
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signingInput + "." + b64.EncodeToString(sig)
+}
+
+func validClaims(now time.Time) map[string]any {
+	return map[string]any{
+		"iss":   "https://sso.test",
+		"aud":   []string{"url-shortener"},
+		"sub":   "alice",
+		"exp":   now.Add(time.Hour).Unix(),
+		"roles": []string{"editor", "viewer"},
+	}
+}
+
+func newTestJwtAuth(t *testing.T, keys testKeys, repo *mockUserRepo, now time.Time) *jwtAuthenticator {
+	t.Helper()
+
+	jwks, err := NewJwks(context.Background(), keys.writeJwks(t), 0)
+	if err != nil {
+		t.Fatalf("failed to load jwks: %v", err)
+	}
+	a := NewJwtAuthenticator(jwks, repo, JwtConfig{
+		Issuer:      "https://sso.test",
+		Audience:    "url-shortener",
+		Leeway:      time.Minute,
+		CreateUsers: true,
+	}).(*jwtAuthenticator)
+	a.now = func() time.Time { return now }
+	return a
+}
+
+func TestJwt_ValidTokens(t *testing.T) {
+	keys := newTestKeys(t)
+	now := time.Now()
+
+	for _, tc := range []struct{ alg, kid string }{
+		{AlgRS256, "rsa-1"},
+		{AlgES256, "ec-1"},
+	} {
+		t.Run(tc.alg, func(t *testing.T) {
+			repo := &mockUserRepo{}
+			auth := newTestJwtAuth(t, keys, repo, now)
+
+			u, err := auth.Authenticate(context.Background(), keys.sign(t, tc.alg, tc.kid, validClaims(now)))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if u.Name != "https://sso.test#alice" || u.Id == 0 {
+				t.Errorf("unexpected user: %+v", u)
+			}
+			if !slices.Equal(u.Roles, []string{"editor", "viewer"}) {
+				t.Errorf("unexpected roles: %v", u.Roles)
+			}
+		})
+	}
+}
+
+func TestJwt_ReusesExistingUser(t *testing.T) {
+	keys := newTestKeys(t)
+	now := time.Now()
+	repo := &mockUserRepo{
+		users:      []user.User{{Id: 1, Name: "bob"}, {Id: 2, Name: "someone"}},
+		identities: map[[2]string]int{{"https://sso.test", "alice"}: 2},
+	}
+	auth := newTestJwtAuth(t, keys, repo, now)
+
+	for range 2 {
+		u, err := auth.Authenticate(context.Background(), keys.sign(t, AlgRS256, "rsa-1", validClaims(now)))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if u.Id != 2 {
+			t.Errorf("expected existing user 2, got %d", u.Id)
+		}
+	}
+	if len(repo.users) != 2 {
+		t.Errorf("expected no new users, got %d", len(repo.users))
+	}
+}
+
+func TestJwt_SubjectNeverMatchesUserNames(t *testing.T) {
+	keys := newTestKeys(t)
+	now := time.Now()
+	// an API key user named like the token subject
+	repo := &mockUserRepo{users: []user.User{{Id: 1, Name: "alice", Roles: []string{user.RoleAdmin}}}}
+	auth := newTestJwtAuth(t, keys, repo, now)
+
+	u, err := auth.Authenticate(context.Background(), keys.sign(t, AlgRS256, "rsa-1", validClaims(now)))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if u.Id == 1 {
+		t.Fatal("token subject must not resolve to the API key user")
+	}
+	if len(repo.users) != 2 {
+		t.Errorf("expected a provisioned user, got %d users", len(repo.users))
+	}
+}
+
+func TestJwt_UnknownSubjectWithoutProvisioning(t *testing.T) {
+	keys := newTestKeys(t)
+	now := time.Now()
+	repo := &mockUserRepo{}
+	auth := newTestJwtAuth(t, keys, repo, now)
+	auth.cfg.CreateUsers = false
+
+	_, err := auth.Authenticate(context.Background(), keys.sign(t, AlgRS256, "rsa-1", validClaims(now)))
+	if !errors.Is(err, user.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got: %v", err)
+	}
+	if len(repo.users) != 0 {
+		t.Errorf("expected no users created, got %d", len(repo.users))
+	}
+}
+
+func TestJwt_SpaceSeparatedRoles(t *testing.T) {
+	keys := newTestKeys(t)
+	now := time.Now()
+	auth := newTestJwtAuth(t, keys, &mockUserRepo{}, now)
+
+	claims := validClaims(now)
+	claims["roles"] = "admin viewer"
+	u, err := auth.Authenticate(context.Background(), keys.sign(t, AlgES256, "ec-1", claims))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !slices.Equal(u.Roles, []string{"admin", "viewer"}) {
+		t.Errorf("unexpected roles: %v", u.Roles)
+	}
+}
+
+func TestJwt_Rejected(t *testing.T) {
+	keys := newTestKeys(t)
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{"malformed", func() string { return "not-a-jwt" }},
+		{"expired", func() string {
+			c := validClaims(now)
+			c["exp"] = now.Add(-2 * time.Minute).Unix()
+			return keys.sign(t, AlgRS256, "rsa-1", c)
+		}},
+		{"missing exp", func() string {
+			c := validClaims(now)
+			delete(c, "exp")
+			return keys.sign(t, AlgRS256, "rsa-1", c)
+		}},
+		{"not yet valid", func() string {
+			c := validClaims(now)
+			c["nbf"] = now.Add(10 * time.Minute).Unix()
+			return keys.sign(t, AlgRS256, "rsa-1", c)
+		}},
+		{"wrong issuer", func() string {
+			c := validClaims(now)
+			c["iss"] = "https://evil.test"
+			return keys.sign(t, AlgRS256, "rsa-1", c)
+		}},
+		{"wrong audience", func() string {
+			c := validClaims(now)
+			c["aud"] = "other-service"
+			return keys.sign(t, AlgRS256, "rsa-1", c)
+		}},
+		{"missing subject", func() string {
+			c := validClaims(now)
+			delete(c, "sub")
+			return keys.sign(t, AlgRS256, "rsa-1", c)
+		}},
+		{"unknown kid", func() string {
+			return keys.sign(t, AlgRS256, "rsa-2", validClaims(now))
+		}},
+		{"key type mismatch", func() string {
+			// an ES256 header pointing at the RSA key
+			token := keys.sign(t, AlgES256, "ec-1", validClaims(now))
+			header := b64.EncodeToString([]byte(`{"alg":"ES256","kid":"rsa-1"}`))
+			return header + token[strings.Index(token, "."):]
+		}},
+		{"tampered claims", func() string {
+			token := keys.sign(t, AlgRS256, "rsa-1", validClaims(now))
+			parts := strings.Split(token, ".")
+			c := validClaims(now)
+			c["sub"] = "mallory"
+			payload, _ := json.Marshal(c)
+			return parts[0] + "." + b64.EncodeToString(payload) + "." + parts[2]
+		}},
+		{"alg none", func() string {
+			header := b64.EncodeToString([]byte(`{"alg":"none"}`))
+			payload, _ := json.Marshal(validClaims(now))
+			return header + "." + b64.EncodeToString(payload) + "."
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := newTestJwtAuth(t, keys, &mockUserRepo{}, now)
+			_, err := auth.Authenticate(context.Background(), tt.token())
+			if !errors.Is(err, user.ErrUnauthorized) {
+				t.Errorf("expected ErrUnauthorized, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestJwks_FromUrlReloadsOnUnknownKid(t *testing.T) {
+	keys := newTestKeys(t)
+	point, _ := keys.ec.PublicKey.Bytes()
+	served, _ := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "EC", "kid": "old", "crv": "P-256",
+		"x": b64.EncodeToString(point[1:33]), "y": b64.EncodeToString(point[33:]),
+	}}})
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(served)
+	}))
+	defer srv.Close()
+
+	jwks, err := NewJwks(context.Background(), srv.URL, 0)
+	if err != nil {
+		t.Fatalf("failed to load jwks: %v", err)
+	}
+	now := jwks.fetchedAt
+	jwks.now = func() time.Time { return now }
+
+	// the signing key rotated after the set was fetched
+	served = keys.jwks
+	if _, err := jwks.Key(context.Background(), "rsa-1"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey before the refetch interval, got: %v", err)
+	}
+
+	now = now.Add(jwksMinRefetch)
+	if _, err := jwks.Key(context.Background(), "rsa-1"); err != nil {
+		t.Errorf("expected rotated key after refetch, got: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 fetches, got %d", requests)
+	}
+}
+
+func TestJwks_KeepsKeysWhenRefreshFails(t *testing.T) {
+	keys := newTestKeys(t)
+	var requests atomic.Int32
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write(keys.jwks)
+	}))
+	defer srv.Close()
+
+	jwks, err := NewJwks(context.Background(), srv.URL, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to load jwks: %v", err)
+	}
+	var mu sync.Mutex
+	now := jwks.fetchedAt.Add(time.Hour)
+	jwks.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		now = now.Add(d)
+		mu.Unlock()
+	}
+	waitReload := func() {
+		// a due refresh runs in the background; joining it waits for it
+		<-jwks.loads.DoChan("", func() (any, error) { return nil, nil })
+	}
+
+	failing.Store(true)
+	if _, err := jwks.Key(context.Background(), "rsa-1"); err != nil {
+		t.Fatalf("expected the cached key while refreshing, got: %v", err)
+	}
+	waitReload()
+	if requests.Load() != 2 {
+		t.Fatalf("expected a refresh attempt, got %d requests", requests.Load())
+	}
+
+	// failed refreshes back off instead of refetching on every request
+	for range 3 {
+		if _, err := jwks.Key(context.Background(), "rsa-1"); err != nil {
+			t.Fatalf("expected the cached key, got: %v", err)
+		}
+	}
+	if _, err := jwks.Key(context.Background(), "rsa-2"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey, got: %v", err)
+	}
+	waitReload()
+	if requests.Load() != 2 {
+		t.Errorf("expected no fetch during backoff, got %d requests", requests.Load())
+	}
+
+	advance(jwksRetryMin)
+	failing.Store(false)
+	if _, err := jwks.Key(context.Background(), "rsa-1"); err != nil {
+		t.Fatalf("expected the cached key, got: %v", err)
+	}
+	waitReload()
+	if requests.Load() != 3 {
+		t.Errorf("expected a retry after the backoff, got %d requests", requests.Load())
+	}
+	jwks.mu.Lock()
+	defer jwks.mu.Unlock()
+	if jwks.failures != 0 || !jwks.fetchedAt.Equal(now) {
+		t.Errorf("expected a successful refresh, got %d failures, fetched at %v", jwks.failures, jwks.fetchedAt)
+	}
+}
+
+func TestJwks_Backoff(t *testing.T) {
+	for failures, want := range map[int]time.Duration{
+		1:  jwksRetryMin,
+		2:  2 * jwksRetryMin,
+		3:  4 * jwksRetryMin,
+		50: jwksRetryMax,
+	} {
+		if got := jwksBackoff(failures); got != want {
+			t.Errorf("jwksBackoff(%d) = %v, want %v", failures, got, want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// cursorToken is the JSON form of an opaque page cursor. It records the sort
+// it was issued for so it cannot resume a listing in a different order.
+type cursorToken struct {
+	Sort      string    `json:"s"`
+	Desc      bool      `json:"d,omitempty"`
+	CreatedAt time.Time `json:"c"`
+	Clicks    int       `json:"k"`
+	Id        int       `json:"i"`
+}
+
+// normalizeListQuery applies defaults to q and checks its filters.
+func normalizeListQuery(q url.ListQuery) (url.ListQuery, error) {
+	switch q.Sort {
+	case "":
+		q.Sort = url.SortCreated
+	case url.SortCreated, url.SortClicks:
+	default:
+		return url.ListQuery{}, fmt.Errorf("%w: unknown sort %q", url.ErrInvalidFilter, q.Sort)
+	}
+
+	f, err := normalizeListFilter(q.ListFilter)
+	if err != nil {
+		return url.ListQuery{}, err
+	}
+	q.ListFilter = f
+
+	switch {
+	case q.Limit < 0:
+		return url.ListQuery{}, fmt.Errorf("%w: limit must not be negative", url.ErrInvalidFilter)
+	case q.Limit == 0:
+		q.Limit = defaultListLimit
+	case q.Limit > maxListLimit:
+		q.Limit = maxListLimit
+	}
+
+	return q, nil
+}
+
+// normalizeListFilter checks the filters of f and normalizes its tags.
+func normalizeListFilter(f url.ListFilter) (url.ListFilter, error) {
+	switch f.State {
+	case "", url.StateActive, url.StateExpired:
+	default:
+		return url.ListFilter{}, fmt.Errorf("%w: unknown state %q", url.ErrInvalidFilter, f.State)
+	}
+
+	if !f.CreatedFrom.IsZero() && !f.CreatedTo.IsZero() && !f.CreatedFrom.Before(f.CreatedTo) {
+		return url.ListFilter{}, fmt.Errorf("%w: created range is empty", url.ErrInvalidFilter)
+	}
+
+	tags, err := normalizeTags(f.Tags)
+	if err != nil {
+		return url.ListFilter{}, fmt.Errorf("%w: %w", url.ErrInvalidFilter, err)
+	}
+	f.Tags = tags
+
+	return f, nil
+}
+
+func encodeCursor(q url.ListQuery, last url.Url) string {
+	raw, _ := json.Marshal(cursorToken{
+		Sort:      q.Sort,
+		Desc:      q.Desc,
+		CreatedAt: last.CreatedAt,
+		Clicks:    last.Clicks,
+		Id:        last.Id,
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor returns the position cursor resumes q at, or nil to start from
+// the beginning.
+func decodeCursor(cursor string, q url.ListQuery) (*url.Cursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, url.ErrInvalidCursor
+	}
+	var token cursorToken
+	if err := json.Unmarshal(raw, &token); err != nil || token.Id == 0 {
+		return nil, url.ErrInvalidCursor
+	}
+	if token.Sort != q.Sort || token.Desc != q.Desc {
+		return nil, fmt.Errorf("%w: issued for a different sort", url.ErrInvalidCursor)
+	}
+
+	return &url.Cursor{CreatedAt: token.CreatedAt, Clicks: token.Clicks, Id: token.Id}, nil
+}
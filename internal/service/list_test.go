@@ -0,0 +1,126 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestList_Paginates(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	links := make([]url.Url, 5)
+	for i := range links {
+		links[i] = url.Url{Id: i + 1, Alias: "a", CreatedAt: created.Add(time.Duration(i) * time.Hour)}
+	}
+	repo := &mockRepo{
+		listFn: func(ctx context.Context) ([]url.Url, error) {
+			return links, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	q := url.ListQuery{Limit: 2}
+	page, err := svc.List(userCtx(), q, "")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if repo.listQuery.Limit != 3 {
+		t.Errorf("expected one row past the limit to be fetched, got limit %d", repo.listQuery.Limit)
+	}
+	if repo.listAfter != nil {
+		t.Errorf("expected the first page to start at the beginning, got %+v", repo.listAfter)
+	}
+	if len(page.Urls) != 2 || page.NextCursor == "" {
+		t.Fatalf("expected 2 urls and a next cursor, got %d urls, cursor %q", len(page.Urls), page.NextCursor)
+	}
+
+	if _, err := svc.List(userCtx(), q, page.NextCursor); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	want := url.Cursor{CreatedAt: links[1].CreatedAt, Id: 2}
+	if repo.listAfter == nil || !repo.listAfter.CreatedAt.Equal(want.CreatedAt) || repo.listAfter.Id != want.Id {
+		t.Errorf("expected to resume after %+v, got %+v", want, repo.listAfter)
+	}
+}
+
+func TestList_NormalizesQuery(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockRepo{
+		listFn: func(ctx context.Context) ([]url.Url, error) {
+			return nil, nil
+		},
+	}
+	policy := testAliasPolicy
+	policy.CaseSensitive = false
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, policy, false)
+	svc.(*urlService).now = func() time.Time { return now }
+
+	q := url.ListQuery{
+		ListFilter: url.ListFilter{AliasPrefix: "Docs", Tags: []string{"Sale", " sale"}, State: url.StateActive},
+		Limit:      10000,
+	}
+	if _, err := svc.List(userCtx(), q, ""); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	got := repo.listQuery
+	if got.Sort != url.SortCreated {
+		t.Errorf("expected default sort %q, got %q", url.SortCreated, got.Sort)
+	}
+	if got.Limit != maxListLimit+1 {
+		t.Errorf("expected limit capped at %d, got %d", maxListLimit, got.Limit-1)
+	}
+	if got.AliasPrefix != "docs" {
+		t.Errorf("expected normalized alias prefix, got %q", got.AliasPrefix)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "sale" {
+		t.Errorf("expected normalized tags, got %q", got.Tags)
+	}
+	if !got.Now.Equal(now) {
+		t.Errorf("expected state judged at %v, got %v", now, got.Now)
+	}
+}
+
+func TestList_Rejected(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdCursor := encodeCursor(url.ListQuery{Sort: url.SortCreated}, url.Url{Id: 1, CreatedAt: from})
+
+	tests := []struct {
+		name    string
+		q       url.ListQuery
+		cursor  string
+		wantErr error
+	}{
+		{"unknown sort", url.ListQuery{Sort: "alias"}, "", url.ErrInvalidFilter},
+		{"unknown state", url.ListQuery{ListFilter: url.ListFilter{State: "deleted"}}, "", url.ErrInvalidFilter},
+		{"negative limit", url.ListQuery{Limit: -1}, "", url.ErrInvalidFilter},
+		{"empty tag", url.ListQuery{ListFilter: url.ListFilter{Tags: []string{""}}}, "", url.ErrInvalidFilter},
+		{
+			"empty created range",
+			url.ListQuery{ListFilter: url.ListFilter{CreatedFrom: from, CreatedTo: from}},
+			"",
+			url.ErrInvalidFilter,
+		},
+		{"garbage cursor", url.ListQuery{}, "not a cursor", url.ErrInvalidCursor},
+		{"cursor for another sort", url.ListQuery{Sort: url.SortClicks}, createdCursor, url.ErrInvalidCursor},
+		{"cursor for another direction", url.ListQuery{Desc: true}, createdCursor, url.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{
+				listFn: func(ctx context.Context) ([]url.Url, error) {
+					t.Fatal("repository must not be queried")
+					return nil, nil
+				},
+			}
+			svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+			if _, err := svc.List(userCtx(), tt.q, tt.cursor); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
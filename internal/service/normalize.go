@@ -0,0 +1,112 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+const maxUrlLength = 2048
+
+var allowedSchemes = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// NormalizeUrl validates a destination URL and returns its canonical form:
+// lower-cased scheme and host, IDN hosts converted to punycode, default ports
+// dropped and the trailing slash of a bare root path trimmed. Only absolute
+// http(s) URLs with a host and without embedded credentials are accepted.
+func NormalizeUrl(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("%w: url is empty", url.ErrInvalidUrl)
+	}
+	if len(raw) > maxUrlLength {
+		return "", fmt.Errorf("%w: url exceeds %d characters", url.ErrInvalidUrl, maxUrlLength)
+	}
+
+	u, err := neturl.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", url.ErrInvalidUrl, err.Error())
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	defaultPort, ok := allowedSchemes[scheme]
+	if !ok {
+		return "", fmt.Errorf("%w: scheme %q is not allowed", url.ErrInvalidUrl, u.Scheme)
+	}
+	if u.User != nil {
+		return "", fmt.Errorf("%w: credentials are not allowed", url.ErrInvalidUrl)
+	}
+
+	host := strings.TrimSuffix(u.Hostname(), ".")
+	if host == "" {
+		return "", fmt.Errorf("%w: host is required", url.ErrInvalidUrl)
+	}
+	if net.ParseIP(host) == nil {
+		host, err = idna.Lookup.ToASCII(host)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid host: %s", url.ErrInvalidUrl, err.Error())
+		}
+	}
+	host = strings.ToLower(host)
+
+	port := u.Port()
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return "", fmt.Errorf("%w: invalid port %q", url.ErrInvalidUrl, port)
+		}
+		if port == defaultPort {
+			port = ""
+		}
+	}
+
+	u.Scheme = scheme
+	u.Host = host
+	if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	}
+	if port != "" {
+		u.Host += ":" + port
+	}
+	if u.Path == "/" {
+		u.Path = ""
+		u.RawPath = ""
+	}
+	u.ForceQuery = false
+
+	normalized := u.String()
+	if len(normalized) > maxUrlLength {
+		return "", fmt.Errorf("%w: url exceeds %d characters", url.ErrInvalidUrl, maxUrlLength)
+	}
+
+	return normalized, nil
+}
+
+// HashUrl returns the dedupe key for an already normalized URL.
+func HashUrl(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeHost reduces a Host header or configured domain to the form domains
+// are stored in: lower-cased, punycode, without port or trailing dot.
+func NormalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimSpace(host), ".")
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+
+	return strings.ToLower(host)
+}
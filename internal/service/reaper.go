@@ -0,0 +1,86 @@
+package service
+
+import (
+	"awesomeProject/internal/repositiries"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	ReaperModePurge   = "purge"
+	ReaperModeArchive = "archive"
+)
+
+// ExpiredReaper periodically removes expired links, either deleting them
+// outright or moving them to the url_archive table.
+type ExpiredReaper struct {
+	repo     repositiries.UrlRepository
+	log      *slog.Logger
+	interval time.Duration
+	mode     string
+}
+
+func NewExpiredReaper(
+	repo repositiries.UrlRepository,
+	logger *slog.Logger,
+	interval time.Duration,
+	mode string,
+) *ExpiredReaper {
+	return &ExpiredReaper{
+		repo:     repo,
+		log:      logger,
+		interval: interval,
+		mode:     mode,
+	}
+}
+
+// Run reaps expired links every interval until ctx is cancelled.
+// A non-positive interval disables the reaper.
+func (r *ExpiredReaper) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := r.Reap(ctx)
+			if err != nil {
+				r.log.Error(
+					"failed to reap expired urls",
+					slog.String("mode", r.mode),
+					slog.String("err", err.Error()),
+				)
+				continue
+			}
+			if n > 0 {
+				r.log.Info(
+					"reaped expired urls",
+					slog.String("mode", r.mode),
+					slog.Int64("count", n),
+				)
+			}
+		}
+	}
+}
+
+// Reap removes the links expired by now. An unknown mode fails instead of
+// falling back to deleting them.
+func (r *ExpiredReaper) Reap(ctx context.Context) (int64, error) {
+	now := time.Now()
+	switch r.mode {
+	case ReaperModeArchive:
+		return r.repo.ArchiveExpired(ctx, now)
+	case ReaperModePurge:
+		return r.repo.DeleteExpired(ctx, now)
+	default:
+		return 0, fmt.Errorf("unknown reaper mode %q", r.mode)
+	}
+}
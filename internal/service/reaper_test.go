@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReap_PurgeMode(t *testing.T) {
+	repo := &mockRepo{
+		deleteExpFn: func(ctx context.Context, now time.Time) (int64, error) {
+			return 3, nil
+		},
+		archiveExpFn: func(ctx context.Context, now time.Time) (int64, error) {
+			t.Error("archive should not be called in purge mode")
+			return 0, nil
+		},
+	}
+	reaper := NewExpiredReaper(repo, newLogger(), time.Minute, ReaperModePurge)
+
+	n, err := reaper.Reap(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 reaped, got %d", n)
+	}
+}
+
+func TestReap_ArchiveMode(t *testing.T) {
+	repo := &mockRepo{
+		deleteExpFn: func(ctx context.Context, now time.Time) (int64, error) {
+			t.Error("delete should not be called in archive mode")
+			return 0, nil
+		},
+		archiveExpFn: func(ctx context.Context, now time.Time) (int64, error) {
+			return 2, nil
+		},
+	}
+	reaper := NewExpiredReaper(repo, newLogger(), time.Minute, ReaperModeArchive)
+
+	n, err := reaper.Reap(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 reaped, got %d", n)
+	}
+}
+
+func TestReap_UnknownMode(t *testing.T) {
+	repo := &mockRepo{
+		deleteExpFn: func(ctx context.Context, now time.Time) (int64, error) {
+			t.Error("delete should not be called for an unknown mode")
+			return 0, nil
+		},
+		archiveExpFn: func(ctx context.Context, now time.Time) (int64, error) {
+			t.Error("archive should not be called for an unknown mode")
+			return 0, nil
+		},
+	}
+	reaper := NewExpiredReaper(repo, newLogger(), time.Minute, "archiv")
+
+	if _, err := reaper.Reap(context.Background()); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+func (s *urlService) Search(ctx context.Context, query string, limit int) ([]url.SearchHit, error) {
+	scope, err := linkScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w: search needs at least one word", url.ErrInvalidFilter)
+	}
+	switch {
+	case limit <= 0:
+		limit = defaultSearchLimit
+	case limit > maxSearchLimit:
+		limit = maxSearchLimit
+	}
+
+	hits, err := s.repo.Search(ctx, scope, prefixQuery(terms), limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hits {
+		hits[i].Highlights = highlights(hits[i].Url, terms)
+	}
+
+	return hits, nil
+}
+
+// searchTerms splits a query into lower case words the way the search vector
+// migration splits links: on every character that is not a letter or digit.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), isSeparator)
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// prefixQuery builds a tsquery matching links containing a word starting with
+// each of terms. Terms hold letters and digits only, so need no quoting.
+func prefixQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = term + ":*"
+	}
+	return strings.Join(parts, " & ")
+}
+
+func highlights(u url.Url, terms []string) map[string]string {
+	out := make(map[string]string)
+	for field, value := range map[string]string{
+		"alias":        u.Alias,
+		"tags":         strings.Join(u.Tags, ", "),
+		"title":        u.Title,
+		"original_url": u.OriginalUrl,
+	} {
+		if marked, ok := highlight(value, terms); ok {
+			out[field] = marked
+		}
+	}
+	return out
+}
+
+// highlight HTML-escapes text and wraps the words starting with one of terms
+// in <mark> tags. It reports whether any word matched.
+func highlight(text string, terms []string) (string, bool) {
+	var b strings.Builder
+	matched := false
+
+	for len(text) > 0 {
+		// alternate between runs of separators and runs of word characters
+		end := strings.IndexFunc(text, func(r rune) bool { return !isSeparator(r) })
+		if end != 0 {
+			if end < 0 {
+				end = len(text)
+			}
+			b.WriteString(html.EscapeString(text[:end]))
+			text = text[end:]
+			continue
+		}
+
+		end = strings.IndexFunc(text, isSeparator)
+		if end < 0 {
+			end = len(text)
+		}
+		word := text[:end]
+		text = text[end:]
+
+		if matchesTerm(strings.ToLower(word), terms) {
+			matched = true
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+	}
+
+	return b.String(), matched
+}
+
+func matchesTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
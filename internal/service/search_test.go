@@ -0,0 +1,95 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/workspace"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSearch_BuildsPrefixQuery(t *testing.T) {
+	var gotQuery string
+	var gotLimit int
+	repo := &mockRepo{
+		searchFn: func(ctx context.Context, tsquery string, limit int) ([]url.SearchHit, error) {
+			gotQuery, gotLimit = tsquery, limit
+			return []url.SearchHit{{
+				Url: url.Url{
+					Id:          1,
+					Alias:       "may-news",
+					OriginalUrl: "https://example.com/Newsletter/may?a=1&b=2",
+					Details:     url.Details{Title: "Newsletter for May", Tags: []string{"mailing", "newsletters"}},
+				},
+				Rank: 0.5,
+			}}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	hits, err := svc.Search(workspaceCtx(workspace.Workspace{Id: 7}), "  Newslet, MAY's ", 0)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if gotQuery != "newslet:* & may:* & s:*" {
+		t.Errorf("unexpected tsquery: %q", gotQuery)
+	}
+	if gotLimit != defaultSearchLimit {
+		t.Errorf("expected default limit %d, got %d", defaultSearchLimit, gotLimit)
+	}
+	if repo.scope != (url.Scope{WorkspaceId: 7}) {
+		t.Errorf("expected search scoped to the workspace, got %+v", repo.scope)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("expected 1 hit, got %d", len(hits))
+	}
+
+	want := map[string]string{
+		"alias":        "<mark>may</mark>-news",
+		"original_url": "https://example.com/<mark>Newsletter</mark>/<mark>may</mark>?a=1&amp;b=2",
+		"title":        "<mark>Newsletter</mark> for <mark>May</mark>",
+		"tags":         "mailing, <mark>newsletters</mark>",
+	}
+	for field, marked := range want {
+		if got := hits[0].Highlights[field]; got != marked {
+			t.Errorf("highlight of %s = %q, want %q", field, got, marked)
+		}
+	}
+}
+
+func TestSearch_Rejected(t *testing.T) {
+	repo := &mockRepo{
+		searchFn: func(ctx context.Context, tsquery string, limit int) ([]url.SearchHit, error) {
+			t.Fatal("repository must not be queried")
+			return nil, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	for _, q := range []string{"", "  ", "&|!:*"} {
+		if _, err := svc.Search(userCtx(), q, 0); !errors.Is(err, url.ErrInvalidFilter) {
+			t.Errorf("Search(%q): expected ErrInvalidFilter, got: %v", q, err)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text    string
+		terms   []string
+		want    string
+		matched bool
+	}{
+		{"summer-sale", []string{"sale"}, "summer-<mark>sale</mark>", true},
+		{"Résumé tips", []string{"résu"}, "<mark>Résumé</mark> tips", true},
+		{"<b>promo</b>", []string{"promo"}, "&lt;b&gt;<mark>promo</mark>&lt;/b&gt;", true},
+		{"https://example.com", []string{"news"}, "https://example.com", false},
+	}
+
+	for _, tt := range tests {
+		got, matched := highlight(tt.text, tt.terms)
+		if got != tt.want || matched != tt.matched {
+			t.Errorf("highlight(%q, %q) = %q, %v, want %q, %v", tt.text, tt.terms, got, matched, tt.want, tt.matched)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/click"
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/workspace"
+	"awesomeProject/internal/repositiries"
+	"awesomeProject/pkg/logger"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// maxBuckets bounds the length of a returned series per interval.
+var maxBuckets = map[string]time.Duration{
+	click.IntervalHour: 31 * 24 * time.Hour,
+	click.IntervalDay:  366 * 24 * time.Hour,
+}
+
+type StatsService interface {
+	// Stats reports clicks on the link alias served on host, or on the
+	// workspace's default domain when host is empty.
+	Stats(ctx context.Context, host, alias string, from, to time.Time, interval string) (click.Stats, error)
+}
+
+type statsService struct {
+	urls   repositiries.UrlRepository
+	clicks repositiries.ClickRepository
+	log    *slog.Logger
+	// policy normalizes aliases the way links were stored
+	policy AliasPolicy
+}
+
+func NewStatsService(
+	urls repositiries.UrlRepository,
+	clicks repositiries.ClickRepository,
+	logger *slog.Logger,
+	policy AliasPolicy,
+) StatsService {
+	return &statsService{
+		urls:   urls,
+		clicks: clicks,
+		log:    logger,
+		policy: policy,
+	}
+}
+
+func (s *statsService) Stats(
+	ctx context.Context,
+	host, alias string,
+	from, to time.Time,
+	interval string,
+) (click.Stats, error) {
+	maxRange, ok := maxBuckets[interval]
+	if !ok {
+		return click.Stats{}, click.ErrInvalidInterval
+	}
+	if !from.Before(to) || to.Sub(from) > maxRange {
+		return click.Stats{}, click.ErrInvalidRange
+	}
+
+	scope, err := linkScope(ctx)
+	if err != nil {
+		return click.Stats{}, err
+	}
+
+	if host == "" {
+		ws, _ := workspace.FromContext(ctx)
+		host = ws.Domain
+	}
+	u, err := s.urls.GetByAlias(ctx, NormalizeHost(host), s.policy.Normalize(alias))
+	if err != nil {
+		return click.Stats{}, err
+	}
+	if !scope.Contains(u) {
+		return click.Stats{}, url.ErrNotFound
+	}
+
+	stats, err := s.clicks.Stats(ctx, u.Id, from, to, interval)
+	if err != nil {
+		s.log.Error(
+			"failed to load click stats",
+			slog.String("alias", alias),
+			slog.String("request_id", logger.RequestIDFromContext(ctx)),
+			slog.String("err", err.Error()),
+		)
+		return click.Stats{}, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/click"
+	"awesomeProject/internal/domain/url"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStats_Success(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(48 * time.Hour)
+	urls := &mockRepo{
+		getByAliasFn: func(ctx context.Context, alias string) (url.Url, error) {
+			return url.Url{Id: 9, Alias: alias, OwnerId: testUserId}, nil
+		},
+	}
+	clicks := &mockClickRepo{
+		statsFn: func(ctx context.Context, urlId int, gotFrom, gotTo time.Time, interval string) (click.Stats, error) {
+			if urlId != 9 {
+				t.Errorf("expected url id 9, got %d", urlId)
+			}
+			if !gotFrom.Equal(from) || !gotTo.Equal(to) || interval != click.IntervalDay {
+				t.Errorf("unexpected args: %v %v %s", gotFrom, gotTo, interval)
+			}
+			return click.Stats{TotalClicks: 5, UniqueVisitors: 2}, nil
+		},
+	}
+	svc := NewStatsService(urls, clicks, newLogger(), testAliasPolicy)
+
+	stats, err := svc.Stats(userCtx(), "", "abc", from, to, click.IntervalDay)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if stats.TotalClicks != 5 || stats.UniqueVisitors != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestStats_Validation(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		to       time.Time
+		interval string
+		want     error
+	}{
+		{"unknown interval", from.Add(time.Hour), "week", click.ErrInvalidInterval},
+		{"empty range", from, click.IntervalHour, click.ErrInvalidRange},
+		{"reversed range", from.Add(-time.Hour), click.IntervalDay, click.ErrInvalidRange},
+		{"too many hours", from.Add(32 * 24 * time.Hour), click.IntervalHour, click.ErrInvalidRange},
+		{"too many days", from.Add(367 * 24 * time.Hour), click.IntervalDay, click.ErrInvalidRange},
+	}
+
+	svc := NewStatsService(&mockRepo{}, &mockClickRepo{}, newLogger(), testAliasPolicy)
+	for _, tc := range tests {
+		_, err := svc.Stats(context.Background(), "", "abc", from, tc.to, tc.interval)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got: %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestStats_NotFound(t *testing.T) {
+	urls := &mockRepo{
+		getByAliasFn: func(ctx context.Context, alias string) (url.Url, error) {
+			return url.Url{}, url.ErrNotFound
+		},
+	}
+	svc := NewStatsService(urls, &mockClickRepo{}, newLogger(), testAliasPolicy)
+
+	from := time.Now().Add(-time.Hour)
+	_, err := svc.Stats(userCtx(), "", "missing", from, time.Now(), click.IntervalHour)
+	if !errors.Is(err, url.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestStats_OtherOwner(t *testing.T) {
+	urls := &mockRepo{
+		getByAliasFn: func(ctx context.Context, alias string) (url.Url, error) {
+			return url.Url{Id: 9, Alias: alias, OwnerId: testUserId + 1}, nil
+		},
+	}
+	svc := NewStatsService(urls, &mockClickRepo{}, newLogger(), testAliasPolicy)
+
+	from := time.Now().Add(-time.Hour)
+	_, err := svc.Stats(userCtx(), "", "theirs", from, time.Now(), click.IntervalHour)
+	if !errors.Is(err, url.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestStats_AdminSeesOtherOwners(t *testing.T) {
+	urls := &mockRepo{
+		getByAliasFn: func(ctx context.Context, alias string) (url.Url, error) {
+			return url.Url{Id: 9, Alias: alias, OwnerId: testUserId + 1}, nil
+		},
+	}
+	clicks := &mockClickRepo{
+		statsFn: func(ctx context.Context, urlId int, from, to time.Time, interval string) (click.Stats, error) {
+			return click.Stats{TotalClicks: 3}, nil
+		},
+	}
+	svc := NewStatsService(urls, clicks, newLogger(), testAliasPolicy)
+
+	from := time.Now().Add(-time.Hour)
+	stats, err := svc.Stats(adminCtx(), "", "theirs", from, time.Now(), click.IntervalHour)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if stats.TotalClicks != 3 {
+		t.Errorf("expected 3 clicks, got %d", stats.TotalClicks)
+	}
+}
+
+func TestStats_NormalizesAlias(t *testing.T) {
+	var looked string
+	urls := &mockRepo{
+		getByAliasFn: func(ctx context.Context, alias string) (url.Url, error) {
+			looked = alias
+			return url.Url{Id: 9, Alias: alias, OwnerId: testUserId}, nil
+		},
+	}
+	clicks := &mockClickRepo{
+		statsFn: func(ctx context.Context, urlId int, from, to time.Time, interval string) (click.Stats, error) {
+			return click.Stats{}, nil
+		},
+	}
+	policy := testAliasPolicy
+	policy.CaseSensitive = false
+	svc := NewStatsService(urls, clicks, newLogger(), policy)
+
+	from := time.Now().Add(-time.Hour)
+	if _, err := svc.Stats(userCtx(), "", "MyAlias", from, time.Now(), click.IntervalHour); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if looked != "myalias" {
+		t.Errorf("expected the alias looked up as stored, got %q", looked)
+	}
+}
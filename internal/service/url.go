@@ -2,117 +2,371 @@ package service
 
 import (
 	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/workspace"
 	"awesomeProject/internal/repositiries"
 	"awesomeProject/pkg/logger"
 	"context"
 	"errors"
+	"expvar"
 	"log/slog"
 	"strings"
+	"time"
+)
+
+const (
+	maxAliasAttempts = 8
+	// aliasGrowEvery is how many collisions are tolerated at one length
+	// before a resizable generator is asked for longer aliases.
+	aliasGrowEvery = 2
+)
+
+var (
+	aliasCollisions     = expvar.NewInt("alias_collisions_total")
+	aliasSpaceExhausted = expvar.NewInt("alias_space_exhausted_total")
 )
 
 type UrlService interface {
-	Save(ctx context.Context, urlToSave, alias string) error
-	List(ctx context.Context) ([]url.Url, error)
+	// Save creates a link on the registered domain host, or on the
+	// workspace's default domain when host is empty.
+	Save(
+		ctx context.Context,
+		host, urlToSave, alias string,
+		expiresAt time.Time,
+		details url.Details,
+	) (url.Url, error)
+	// List returns a page of the caller's links matching q, resuming after
+	// cursor unless it is empty. Follow-up pages must repeat q's filters and
+	// sort.
+	List(ctx context.Context, q url.ListQuery, cursor string) (url.Page, error)
+	// Search finds the caller's links with a word starting with each word of
+	// query in their alias, tags, title or destination, best matches first.
+	Search(ctx context.Context, query string, limit int) ([]url.SearchHit, error)
 	Get(ctx context.Context, id int) (url.Url, error)
-	Update(ctx context.Context, id int, newUrl, alias string) error
+	// GetByAlias resolves alias in the namespace served on host.
+	GetByAlias(ctx context.Context, host, alias string) (url.Url, error)
+	ShortUrl(u url.Url) string
+	Update(
+		ctx context.Context,
+		id int,
+		newUrl, alias string,
+		expiresAt time.Time,
+		details url.DetailsPatch,
+	) error
 	Delete(ctx context.Context, id int) error
+	// SaveMany, UpdateMany and DeleteMany apply a batch of creations,
+	// updates or deletions and report the outcome per item, in order. Items
+	// fail on their own; an error is only returned if the whole batch did.
+	SaveMany(ctx context.Context, drafts []url.Draft) ([]url.ItemResult, error)
+	UpdateMany(ctx context.Context, changes []url.Change) ([]url.ItemResult, error)
+	DeleteMany(ctx context.Context, ids []int) ([]url.ItemResult, error)
+	// CheckMany validates drafts like SaveMany without storing anything.
+	// Explicit aliases are checked against the stored links; generated ones
+	// are left blank.
+	CheckMany(ctx context.Context, drafts []url.Draft) ([]url.ItemResult, error)
+	// Export calls fn with each of the caller's links matching f, oldest
+	// first, as they are read. An error from fn stops the export.
+	Export(ctx context.Context, f url.ListFilter, fn func(url.Url) error) error
 }
 
 type urlService struct {
 	repo      repositiries.UrlRepository
+	domains   repositiries.DomainRepository
 	generator AliasGenerator
 	log       *slog.Logger
-	baseUrl   string
+	baseUrl   func(domain string) string
+	policy    AliasPolicy
+	dedupe    bool
+	now       func() time.Time
 }
 
 func NewUrlService(
 	repo repositiries.UrlRepository,
+	domains repositiries.DomainRepository,
 	generator AliasGenerator,
 	logger *slog.Logger,
-	baseUrl string,
+	baseUrl func(domain string) string,
+	policy AliasPolicy,
+	dedupe bool,
 ) UrlService {
 	return &urlService{
 		repo:      repo,
+		domains:   domains,
 		generator: generator,
 		log:       logger,
 		baseUrl:   baseUrl,
+		policy:    policy,
+		dedupe:    dedupe,
+		now:       time.Now,
 	}
 }
 
-func (s *urlService) Save(ctx context.Context, urlToSave, alias string) error {
+func (s *urlService) Save(
+	ctx context.Context,
+	host, urlToSave, alias string,
+	expiresAt time.Time,
+	details url.Details,
+) (url.Url, error) {
 	log := s.log.With(
 		slog.String("url", urlToSave),
 		slog.String("alias", alias),
 		slog.String("request_id", logger.RequestIDFromContext(ctx)),
 	)
 
-	if alias != "" {
-		shortUrl := s.BuildShortUrl(s.baseUrl, alias)
-		err := s.repo.Save(ctx, urlToSave, shortUrl)
+	ownerId, err := callerId(ctx)
+	if err != nil {
+		return url.Url{}, err
+	}
+	ws, _ := workspace.FromContext(ctx)
+	d, err := s.resolveDomain(ctx, ws, host)
+	if err != nil {
+		return url.Url{}, err
+	}
+	link, err := s.newLink(ctx, ws, ownerId, url.Draft{
+		OriginalUrl: urlToSave,
+		Alias:       alias,
+		ExpiresAt:   expiresAt,
+		Details:     details,
+	}, d)
+	if err != nil {
+		return url.Url{}, err
+	}
+
+	if link.Alias != "" {
+		u, err := s.repo.Save(ctx, link, "")
 		if err != nil {
 			log.Error(
 				"failed to save url",
 				slog.String("err", err.Error()),
 			)
-			return err
+			return url.Url{}, err
+		}
+		return u, nil
+	}
+
+	// Only links without an expiry or details are deduplicated: handing out
+	// an existing link could otherwise give the caller a different lifetime
+	// or description than asked.
+	var urlHash string
+	if s.dedupe && link.ExpiresAt.IsZero() && link.Details.IsZero() {
+		urlHash = HashUrl(link.OriginalUrl)
+		existing, err := s.repo.GetByHash(ctx, link, urlHash)
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, url.ErrNotFound) {
+			log.Error(
+				"failed to look up existing url",
+				slog.String("err", err.Error()),
+			)
+			return url.Url{}, err
 		}
-		return nil
 	}
 
-	for i := 0; i < 5; i++ {
-		alias = s.generator.Generate()
-		shortUrl := s.BuildShortUrl(s.baseUrl, alias)
-		err := s.repo.Save(ctx, urlToSave, shortUrl)
+	for attempt := 0; attempt < maxAliasAttempts; attempt++ {
+		alias, err = s.generateAlias(ctx, log, attempt)
+		if err != nil {
+			log.Error(
+				"failed to generate alias",
+				slog.String("err", err.Error()),
+			)
+			return url.Url{}, err
+		}
+		if s.policy.IsReserved(alias) {
+			continue
+		}
+		link.Alias = alias
+		u, err := s.repo.Save(ctx, link, urlHash)
 		if err == nil {
-			break
+			return u, nil
 		}
 		if errors.Is(err, url.ErrAliasTaken) {
+			aliasCollisions.Add(1)
 			continue
 		}
+		if errors.Is(err, url.ErrDuplicateUrl) {
+			// lost a race with a concurrent request for the same url
+			existing, err := s.repo.GetByHash(ctx, link, urlHash)
+			if err != nil {
+				return url.Url{}, err
+			}
+			return existing, nil
+		}
 		log.Error(
 			"failed to save url",
 			slog.String("err", err.Error()),
 		)
-		return err
+		return url.Url{}, err
 	}
 
-	return nil
+	aliasSpaceExhausted.Add(1)
+	log.Error(
+		"alias space exhausted",
+		slog.Int("attempts", maxAliasAttempts),
+	)
+	return url.Url{}, url.ErrAliasSpaceExhausted
 }
 
-func (s *urlService) List(ctx context.Context) ([]url.Url, error) {
-	urls, err := s.repo.List(ctx)
+// newLink validates draft and returns the link to store for it on domain d.
+// The alias is left blank for the caller to generate if draft has none.
+func (s *urlService) newLink(
+	ctx context.Context,
+	ws workspace.Workspace,
+	ownerId int,
+	draft url.Draft,
+	d url.Domain,
+) (url.Url, error) {
+	originalUrl, err := NormalizeUrl(draft.OriginalUrl)
 	if err != nil {
-		return nil, err
+		return url.Url{}, err
+	}
+	if err := s.validateExpiry(draft.ExpiresAt); err != nil {
+		return url.Url{}, err
+	}
+	details, err := normalizeDetails(draft.Details)
+	if err != nil {
+		return url.Url{}, err
+	}
+
+	link := url.Url{
+		OriginalUrl: originalUrl,
+		ExpiresAt:   draft.ExpiresAt,
+		OwnerId:     ownerId,
+		WorkspaceId: ws.Id,
+		DomainId:    d.Id,
+		Domain:      d.Host,
+		Details:     details,
+	}
+	if draft.Alias != "" {
+		link.Alias, err = s.validateAlias(ctx, draft.Alias)
+		if err != nil {
+			return url.Url{}, err
+		}
 	}
 
-	return urls, nil
+	return link, nil
+}
+
+// generateAlias grows the alias every aliasGrowEvery collisions when the
+// generator supports it, so a crowded keyspace degrades into slightly longer
+// links instead of failed requests.
+func (s *urlService) generateAlias(ctx context.Context, log *slog.Logger, attempt int) (string, error) {
+	g, ok := s.generator.(ResizableGenerator)
+	if !ok {
+		return s.generator.Generate(ctx)
+	}
+
+	extra := attempt / aliasGrowEvery
+	if attempt > 0 && attempt%aliasGrowEvery == 0 {
+		log.Warn(
+			"alias collisions, growing alias length",
+			slog.Int("attempt", attempt),
+			slog.Int("extra", extra),
+		)
+	}
+
+	return g.GenerateLonger(ctx, extra)
+}
+
+func (s *urlService) List(ctx context.Context, q url.ListQuery, cursor string) (url.Page, error) {
+	scope, err := linkScope(ctx)
+	if err != nil {
+		return url.Page{}, err
+	}
+
+	q, err = normalizeListQuery(q)
+	if err != nil {
+		return url.Page{}, err
+	}
+	after, err := decodeCursor(cursor, q)
+	if err != nil {
+		return url.Page{}, err
+	}
+	q.AliasPrefix = s.policy.Normalize(q.AliasPrefix)
+	q.Now = s.now()
+
+	// one extra row tells whether another page follows
+	fetch := q
+	fetch.Limit++
+	urls, err := s.repo.List(ctx, scope, fetch, after)
+	if err != nil {
+		return url.Page{}, err
+	}
+
+	page := url.Page{Urls: urls}
+	if len(urls) > q.Limit {
+		page.Urls = urls[:q.Limit]
+		page.NextCursor = encodeCursor(q, page.Urls[q.Limit-1])
+	}
+
+	return page, nil
 }
 
 func (s *urlService) Get(ctx context.Context, id int) (url.Url, error) {
+	scope, err := linkScope(ctx)
+	if err != nil {
+		return url.Url{}, err
+	}
+
 	u, err := s.repo.Get(ctx, id)
 	if err != nil {
 		return url.Url{}, err
 	}
+	// links out of scope are reported as missing rather than forbidden
+	if !scope.Contains(u) {
+		return url.Url{}, url.ErrNotFound
+	}
+
+	return u, nil
+}
+
+func (s *urlService) GetByAlias(ctx context.Context, host, alias string) (url.Url, error) {
+	u, err := s.repo.GetByAlias(ctx, NormalizeHost(host), s.policy.Normalize(alias))
+	if err != nil {
+		return url.Url{}, err
+	}
+	if u.IsExpired(s.now()) {
+		return url.Url{}, url.ErrExpired
+	}
 
 	return u, nil
 }
 
+func (s *urlService) ShortUrl(u url.Url) string {
+	return s.BuildShortUrl(s.baseUrl(u.Domain), u.Alias)
+}
+
 func (s *urlService) BuildShortUrl(baseUrl, code string) string {
 	baseUrl = strings.TrimRight(baseUrl, "/")
 	return baseUrl + "/" + code
 }
 
-func (s *urlService) Update(ctx context.Context, id int, newUrl, alias string) error {
-	var shortUrl string
-	if alias != "" {
-		shortUrl = s.BuildShortUrl(s.baseUrl, alias)
+func (s *urlService) Update(
+	ctx context.Context,
+	id int,
+	newUrl, alias string,
+	expiresAt time.Time,
+	details url.DetailsPatch,
+) error {
+	scope, err := writeScope(ctx)
+	if err != nil {
+		return err
+	}
+	c, err := s.normalizeChange(ctx, url.Change{
+		Id:        id,
+		NewUrl:    newUrl,
+		Alias:     alias,
+		ExpiresAt: expiresAt,
+		Details:   details,
+	})
+	if err != nil {
+		return err
 	}
 
-	err := s.repo.Update(ctx, id, newUrl, shortUrl)
+	err = s.repo.Update(ctx, scope, c.Id, c.NewUrl, c.Alias, c.ExpiresAt, c.Details)
 	if err != nil {
 		s.log.Error(
-			"failed to update url", slog.String("url", newUrl),
-			slog.String("alias", alias),
+			"failed to update url", slog.String("url", c.NewUrl),
+			slog.String("alias", c.Alias),
 		)
 		return err
 	}
@@ -120,11 +374,83 @@ func (s *urlService) Update(ctx context.Context, id int, newUrl, alias string) e
 	return nil
 }
 
+// normalizeChange validates c the way Update does and returns it normalized.
+func (s *urlService) normalizeChange(ctx context.Context, c url.Change) (url.Change, error) {
+	var err error
+	c.NewUrl, err = NormalizeUrl(c.NewUrl)
+	if err != nil {
+		return url.Change{}, err
+	}
+	if err := s.validateExpiry(c.ExpiresAt); err != nil {
+		return url.Change{}, err
+	}
+	if c.Alias != "" {
+		c.Alias, err = s.validateAlias(ctx, c.Alias)
+		if err != nil {
+			return url.Change{}, err
+		}
+	}
+	c.Details, err = normalizeDetailsPatch(c.Details)
+	if err != nil {
+		return url.Change{}, err
+	}
+
+	return c, nil
+}
+
 func (s *urlService) Delete(ctx context.Context, id int) error {
-	err := s.repo.Delete(ctx, id)
+	scope, err := writeScope(ctx)
 	if err != nil {
 		return err
 	}
 
+	err = s.repo.Delete(ctx, scope, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// resolveDomain picks the domain a new link is served on: the registered
+// domain host if given, else the workspace's default domain. The zero Domain
+// stands for the default host. Domains of other workspaces are reported as
+// unknown.
+func (s *urlService) resolveDomain(ctx context.Context, ws workspace.Workspace, host string) (url.Domain, error) {
+	if host == "" {
+		host = ws.Domain
+	}
+	if host == "" {
+		return url.Domain{}, nil
+	}
+
+	d, err := s.domains.GetByHost(ctx, NormalizeHost(host))
+	if err != nil {
+		return url.Domain{}, err
+	}
+	if !d.UsableIn(ws.Id) {
+		return url.Domain{}, url.ErrUnknownDomain
+	}
+
+	return d, nil
+}
+
+// validateAlias checks a caller-supplied alias against the policy. Admins may
+// claim reserved aliases.
+func (s *urlService) validateAlias(ctx context.Context, alias string) (string, error) {
+	policy := s.policy
+	if isAdmin(ctx) {
+		policy.Reserved = nil
+	}
+	return policy.Validate(alias)
+}
+
+// validateExpiry rejects expiries that are already in the past. A zero value
+// means the link does not expire (or, on update, that the expiry is unchanged).
+func (s *urlService) validateExpiry(expiresAt time.Time) error {
+	if !expiresAt.IsZero() && !expiresAt.After(s.now()) {
+		return url.ErrInvalidExpiry
+	}
+
 	return nil
 }
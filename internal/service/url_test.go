@@ -2,52 +2,212 @@ package service
 
 import (
 	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/domain/workspace"
 	"context"
 	"errors"
 	"io"
 	"log/slog"
 	"testing"
+	"time"
 )
 
 // --- Mocks ---
 
 type mockRepo struct {
-	saveFn   func(ctx context.Context, urlToSave, alias string) error
-	listFn   func(ctx context.Context) ([]url.Url, error)
-	getFn    func(ctx context.Context, id int) (url.Url, error)
-	updateFn func(ctx context.Context, id int, newUrl, alias string) error
-	deleteFn func(ctx context.Context, id int) error
+	// recorded arguments of the last scoped call
+	ownerId      int
+	scope        url.Scope
+	saved        url.Url
+	host         string
+	listQuery    url.ListQuery
+	listAfter    *url.Cursor
+	patch        url.DetailsPatch
+	saveFn       func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error)
+	listFn       func(ctx context.Context) ([]url.Url, error)
+	searchFn     func(ctx context.Context, tsquery string, limit int) ([]url.SearchHit, error)
+	getFn        func(ctx context.Context, id int) (url.Url, error)
+	getByAliasFn func(ctx context.Context, alias string) (url.Url, error)
+	getByHashFn  func(ctx context.Context, urlHash string) (url.Url, error)
+	updateFn     func(ctx context.Context, id int, newUrl, alias string, expiresAt time.Time) error
+	deleteFn     func(ctx context.Context, id int) error
+	deleteExpFn  func(ctx context.Context, now time.Time) (int64, error)
+	archiveExpFn func(ctx context.Context, now time.Time) (int64, error)
+	incClicksFn  func(ctx context.Context, clicks map[int]int64) error
+	saveManyFn   func(ctx context.Context, links []url.Url) ([]url.ItemResult, error)
+	updateManyFn func(ctx context.Context, changes []url.Change) ([]error, error)
+	deleteManyFn func(ctx context.Context, ids []int) ([]error, error)
+	takenFn      func(ctx context.Context, links []url.Url) ([]bool, error)
+	exportFn     func(ctx context.Context, fn func(url.Url) error) error
 }
 
-func (m *mockRepo) Save(ctx context.Context, urlToSave, alias string) error {
-	return m.saveFn(ctx, urlToSave, alias)
+func (m *mockRepo) Save(ctx context.Context, u url.Url, urlHash string) (url.Url, error) {
+	m.ownerId = u.OwnerId
+	m.saved = u
+	return m.saveFn(ctx, u.OriginalUrl, u.Alias, urlHash, u.ExpiresAt)
 }
 
-func (m *mockRepo) List(ctx context.Context) ([]url.Url, error) {
+func (m *mockRepo) List(ctx context.Context, scope url.Scope, q url.ListQuery, after *url.Cursor) ([]url.Url, error) {
+	m.ownerId = scope.OwnerId
+	m.scope = scope
+	m.listQuery = q
+	m.listAfter = after
 	return m.listFn(ctx)
 }
 
+func (m *mockRepo) Search(ctx context.Context, scope url.Scope, tsquery string, limit int) ([]url.SearchHit, error) {
+	m.ownerId = scope.OwnerId
+	m.scope = scope
+	return m.searchFn(ctx, tsquery, limit)
+}
+
 func (m *mockRepo) Get(ctx context.Context, id int) (url.Url, error) {
 	return m.getFn(ctx, id)
 }
 
-func (m *mockRepo) Update(ctx context.Context, id int, newUrl, alias string) error {
-	return m.updateFn(ctx, id, newUrl, alias)
+func (m *mockRepo) GetByAlias(ctx context.Context, host, alias string) (url.Url, error) {
+	m.host = host
+	return m.getByAliasFn(ctx, alias)
+}
+
+func (m *mockRepo) GetByHash(ctx context.Context, u url.Url, urlHash string) (url.Url, error) {
+	m.ownerId = u.OwnerId
+	return m.getByHashFn(ctx, urlHash)
 }
 
-func (m *mockRepo) Delete(ctx context.Context, id int) error {
+func (m *mockRepo) Update(
+	ctx context.Context,
+	scope url.Scope,
+	id int,
+	newUrl, alias string,
+	expiresAt time.Time,
+	details url.DetailsPatch,
+) error {
+	m.ownerId = scope.OwnerId
+	m.scope = scope
+	m.patch = details
+	return m.updateFn(ctx, id, newUrl, alias, expiresAt)
+}
+
+func (m *mockRepo) Delete(ctx context.Context, scope url.Scope, id int) error {
+	m.ownerId = scope.OwnerId
+	m.scope = scope
 	return m.deleteFn(ctx, id)
 }
 
+func (m *mockRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return m.deleteExpFn(ctx, now)
+}
+
+func (m *mockRepo) ArchiveExpired(ctx context.Context, now time.Time) (int64, error) {
+	return m.archiveExpFn(ctx, now)
+}
+
+func (m *mockRepo) IncrementClicks(ctx context.Context, clicks map[int]int64) error {
+	return m.incClicksFn(ctx, clicks)
+}
+
+func (m *mockRepo) SaveMany(ctx context.Context, links []url.Url) ([]url.ItemResult, error) {
+	return m.saveManyFn(ctx, links)
+}
+
+func (m *mockRepo) UpdateMany(ctx context.Context, scope url.Scope, changes []url.Change) ([]error, error) {
+	m.ownerId = scope.OwnerId
+	m.scope = scope
+	return m.updateManyFn(ctx, changes)
+}
+
+func (m *mockRepo) DeleteMany(ctx context.Context, scope url.Scope, ids []int) ([]error, error) {
+	m.ownerId = scope.OwnerId
+	m.scope = scope
+	return m.deleteManyFn(ctx, ids)
+}
+
+func (m *mockRepo) TakenAliases(ctx context.Context, links []url.Url) ([]bool, error) {
+	return m.takenFn(ctx, links)
+}
+
+func (m *mockRepo) Export(ctx context.Context, scope url.Scope, f url.ListFilter, fn func(url.Url) error) error {
+	m.ownerId = scope.OwnerId
+	m.scope = scope
+	m.listQuery = url.ListQuery{ListFilter: f}
+	return m.exportFn(ctx, fn)
+}
+
+type mockDomainRepo struct {
+	domains []url.Domain
+}
+
+func (m *mockDomainRepo) Create(ctx context.Context, host string, workspaceId int) (url.Domain, error) {
+	for _, d := range m.domains {
+		if d.Host == host {
+			return url.Domain{}, url.ErrDomainTaken
+		}
+	}
+	d := url.Domain{Id: len(m.domains) + 1, Host: host, WorkspaceId: workspaceId}
+	m.domains = append(m.domains, d)
+	return d, nil
+}
+
+func (m *mockDomainRepo) GetByHost(ctx context.Context, host string) (url.Domain, error) {
+	for _, d := range m.domains {
+		if d.Host == host {
+			return d, nil
+		}
+	}
+	return url.Domain{}, url.ErrUnknownDomain
+}
+
+func (m *mockDomainRepo) List(ctx context.Context) ([]url.Domain, error) {
+	return m.domains, nil
+}
+
 type mockGenerator struct {
 	aliases []string
 	index   int
 }
 
-func (m *mockGenerator) Generate() string {
+func (m *mockGenerator) Generate(ctx context.Context) (string, error) {
 	alias := m.aliases[m.index]
 	m.index++
-	return alias
+	return alias, nil
+}
+
+var testAliasPolicy = AliasPolicy{
+	MinLength:     3,
+	MaxLength:     32,
+	Charset:       "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_",
+	Reserved:      []string{"url", "list", "api", "health"},
+	CaseSensitive: true,
+}
+
+const testUserId = 1
+
+// testBaseUrl serves the default host on localhost and custom domains over
+// https.
+func testBaseUrl(domain string) string {
+	if domain == "" {
+		return "http://localhost"
+	}
+	return "https://" + domain
+}
+
+// userCtx returns a context carrying an authenticated caller.
+func userCtx() context.Context {
+	return user.WithUser(context.Background(), user.User{Id: testUserId, Name: "tester"})
+}
+
+// workspaceCtx returns a caller context acting in workspace ws.
+func workspaceCtx(ws workspace.Workspace) context.Context {
+	return workspace.WithWorkspace(userCtx(), ws)
+}
+
+func adminCtx() context.Context {
+	return user.WithUser(context.Background(), user.User{
+		Id:    testUserId,
+		Name:  "admin",
+		Roles: []string{user.RoleAdmin},
+	})
 }
 
 func newLogger() *slog.Logger {
@@ -58,34 +218,52 @@ func newLogger() *slog.Logger {
 
 func TestSave_WithAlias_Success(t *testing.T) {
 	repo := &mockRepo{
-		saveFn: func(ctx context.Context, urlToSave, alias string) error {
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
 			if urlToSave != "https://example.com" {
 				t.Errorf("unexpected url: %s", urlToSave)
 			}
-			if alias != "http://localhost/my-alias" {
+			if alias != "my-alias" {
 				t.Errorf("unexpected alias: %s", alias)
 			}
-			return nil
+			return url.Url{Alias: alias}, nil
 		},
 	}
-	svc := NewUrlService(repo, &mockGenerator{}, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	err := svc.Save(context.Background(), "https://example.com", "my-alias")
+	_, err := svc.Save(userCtx(), "", "https://example.com", "my-alias", time.Time{}, url.Details{})
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
 }
 
+func TestSave_ReturnsCreatedUrl(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			return url.Url{Id: 11, OriginalUrl: urlToSave, Alias: alias, ExpiresAt: expiresAt}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	u, err := svc.Save(userCtx(), "", "https://example.com", "my-alias", expiresAt, url.Details{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if u.Id != 11 || u.Alias != "my-alias" || !u.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("unexpected result: %+v", u)
+	}
+}
+
 func TestSave_WithAlias_RepoError(t *testing.T) {
 	repoErr := errors.New("db error")
 	repo := &mockRepo{
-		saveFn: func(ctx context.Context, urlToSave, alias string) error {
-			return repoErr
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			return url.Url{}, repoErr
 		},
 	}
-	svc := NewUrlService(repo, &mockGenerator{}, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	err := svc.Save(context.Background(), "https://example.com", "my-alias")
+	_, err := svc.Save(userCtx(), "", "https://example.com", "my-alias", time.Time{}, url.Details{})
 	if !errors.Is(err, repoErr) {
 		t.Errorf("expected db error, got: %v", err)
 	}
@@ -94,19 +272,19 @@ func TestSave_WithAlias_RepoError(t *testing.T) {
 func TestSave_WithoutAlias_GeneratesAlias(t *testing.T) {
 	var savedAlias string
 	repo := &mockRepo{
-		saveFn: func(ctx context.Context, urlToSave, alias string) error {
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
 			savedAlias = alias
-			return nil
+			return url.Url{Alias: alias}, nil
 		},
 	}
 	gen := &mockGenerator{aliases: []string{"generated1"}}
-	svc := NewUrlService(repo, gen, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	err := svc.Save(context.Background(), "https://example.com", "")
+	_, err := svc.Save(userCtx(), "", "https://example.com", "", time.Time{}, url.Details{})
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
-	if savedAlias != "http://localhost/generated1" {
+	if savedAlias != "generated1" {
 		t.Errorf("unexpected alias: %s", savedAlias)
 	}
 }
@@ -114,18 +292,18 @@ func TestSave_WithoutAlias_GeneratesAlias(t *testing.T) {
 func TestSave_WithoutAlias_RetryOnAliasTaken(t *testing.T) {
 	callCount := 0
 	repo := &mockRepo{
-		saveFn: func(ctx context.Context, urlToSave, alias string) error {
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
 			callCount++
 			if callCount < 3 {
-				return url.ErrAliasTaken
+				return url.Url{}, url.ErrAliasTaken
 			}
-			return nil
+			return url.Url{Alias: alias}, nil
 		},
 	}
 	gen := &mockGenerator{aliases: []string{"alias1", "alias2", "alias3"}}
-	svc := NewUrlService(repo, gen, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	err := svc.Save(context.Background(), "https://example.com", "")
+	_, err := svc.Save(userCtx(), "", "https://example.com", "", time.Time{}, url.Details{})
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -134,19 +312,91 @@ func TestSave_WithoutAlias_RetryOnAliasTaken(t *testing.T) {
 	}
 }
 
+func TestSave_WithoutAlias_BlockAliasTakenByCustomAlias(t *testing.T) {
+	var tried []string
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			tried = append(tried, alias)
+			if alias == "blk1" {
+				return url.Url{}, url.ErrAliasTaken
+			}
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	gen := NewBlockGenerator(&mockCounter{}, 10, func(id uint64) string { return "blk" + decimal(id) })
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	u, err := svc.Save(userCtx(), "", "https://example.com", "", time.Time{}, url.Details{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if u.Alias != "blk2" {
+		t.Errorf("expected the next block id, got: %q (tried %v)", u.Alias, tried)
+	}
+}
+
 func TestSave_WithoutAlias_AllAliasesTaken(t *testing.T) {
 	repo := &mockRepo{
-		saveFn: func(ctx context.Context, urlToSave, alias string) error {
-			return url.ErrAliasTaken
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			return url.Url{}, url.ErrAliasTaken
+		},
+	}
+	gen := &mockGenerator{aliases: []string{"a1", "a2", "a3", "a4", "a5", "a6", "a7", "a8"}}
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	// Все попытки провалились — сервис должен вернуть ErrAliasSpaceExhausted, а не nil
+	_, err := svc.Save(userCtx(), "", "https://example.com", "", time.Time{}, url.Details{})
+	if !errors.Is(err, url.ErrAliasSpaceExhausted) {
+		t.Errorf("expected ErrAliasSpaceExhausted, got: %v", err)
+	}
+	if gen.index != maxAliasAttempts {
+		t.Errorf("expected %d attempts, got: %d", maxAliasAttempts, gen.index)
+	}
+}
+
+func TestSave_WithoutAlias_GrowsAliasLength(t *testing.T) {
+	var lengths []int
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			lengths = append(lengths, len(alias))
+			if len(lengths) < 5 {
+				return url.Url{}, url.ErrAliasTaken
+			}
+			return url.Url{Alias: alias}, nil
 		},
 	}
-	gen := &mockGenerator{aliases: []string{"a1", "a2", "a3", "a4", "a5"}}
-	svc := NewUrlService(repo, gen, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, NewRandomGenerator(base62Charset), newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	// Все 5 попыток провалились — сервис возвращает nil (цикл завершается без ошибки)
-	err := svc.Save(context.Background(), "https://example.com", "")
+	_, err := svc.Save(userCtx(), "", "https://example.com", "", time.Time{}, url.Details{})
 	if err != nil {
-		t.Errorf("expected nil, got: %v", err)
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	want := []int{8, 8, 9, 9, 10}
+	for i := range want {
+		if lengths[i] != want[i] {
+			t.Errorf("attempt %d: expected length %d, got %d", i, want[i], lengths[i])
+		}
+	}
+}
+
+func TestSave_WithoutAlias_SkipsReservedAlias(t *testing.T) {
+	var saved []string
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			saved = append(saved, alias)
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	gen := &mockGenerator{aliases: []string{"list", "ok1"}}
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	u, err := svc.Save(userCtx(), "", "https://example.com", "", time.Time{}, url.Details{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if u.Alias != "ok1" || len(saved) != 1 {
+		t.Errorf("expected reserved alias to be skipped, saved: %v", saved)
 	}
 }
 
@@ -154,15 +404,15 @@ func TestSave_WithoutAlias_NonAliasError(t *testing.T) {
 	repoErr := errors.New("unexpected db error")
 	callCount := 0
 	repo := &mockRepo{
-		saveFn: func(ctx context.Context, urlToSave, alias string) error {
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
 			callCount++
-			return repoErr
+			return url.Url{}, repoErr
 		},
 	}
 	gen := &mockGenerator{aliases: []string{"alias1", "alias2"}}
-	svc := NewUrlService(repo, gen, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	err := svc.Save(context.Background(), "https://example.com", "")
+	_, err := svc.Save(userCtx(), "", "https://example.com", "", time.Time{}, url.Details{})
 	if !errors.Is(err, repoErr) {
 		t.Errorf("expected repoErr, got: %v", err)
 	}
@@ -171,28 +421,247 @@ func TestSave_WithoutAlias_NonAliasError(t *testing.T) {
 	}
 }
 
+func TestSave_WithExpiry_PassesExpiry(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, got time.Time) (url.Url, error) {
+			if !got.Equal(expiresAt) {
+				t.Errorf("unexpected expiry: %v", got)
+			}
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	_, err := svc.Save(userCtx(), "", "https://example.com", "my-alias", expiresAt, url.Details{})
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestSave_ExpiryInPast(t *testing.T) {
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			t.Error("repo should not be called")
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	_, err := svc.Save(userCtx(), "", "https://example.com", "my-alias", time.Now().Add(-time.Minute), url.Details{})
+	if !errors.Is(err, url.ErrInvalidExpiry) {
+		t.Errorf("expected ErrInvalidExpiry, got: %v", err)
+	}
+}
+
+func TestSave_InvalidUrl(t *testing.T) {
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			t.Error("repo should not be called")
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	_, err := svc.Save(userCtx(), "", "javascript:alert(1)", "my-alias", time.Time{}, url.Details{})
+	if !errors.Is(err, url.ErrInvalidUrl) {
+		t.Errorf("expected ErrInvalidUrl, got: %v", err)
+	}
+}
+
+func TestSave_NormalizesUrl(t *testing.T) {
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			if urlToSave != "https://example.com/a" {
+				t.Errorf("unexpected url: %s", urlToSave)
+			}
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	_, err := svc.Save(userCtx(), "", "HTTPS://Example.com:443/a", "my-alias", time.Time{}, url.Details{})
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestSave_InvalidAlias(t *testing.T) {
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			t.Error("repo should not be called")
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	_, err := svc.Save(userCtx(), "", "https://example.com", "list", time.Time{}, url.Details{})
+	if !errors.Is(err, url.ErrInvalidAlias) {
+		t.Errorf("expected ErrInvalidAlias, got: %v", err)
+	}
+}
+
+func TestSave_RecordsOwner(t *testing.T) {
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	if _, err := svc.Save(userCtx(), "", "https://example.com", "my-alias", time.Time{}, url.Details{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if repo.ownerId != testUserId {
+		t.Errorf("expected owner %d, got %d", testUserId, repo.ownerId)
+	}
+}
+
+func TestScopedMethods_RequireCaller(t *testing.T) {
+	svc := NewUrlService(&mockRepo{}, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+	ctx := context.Background()
+
+	if _, err := svc.Save(ctx, "", "https://example.com", "my-alias", time.Time{}, url.Details{}); !errors.Is(err, user.ErrUnauthorized) {
+		t.Errorf("Save: expected ErrUnauthorized, got: %v", err)
+	}
+	if _, err := svc.List(ctx, url.ListQuery{}, ""); !errors.Is(err, user.ErrUnauthorized) {
+		t.Errorf("List: expected ErrUnauthorized, got: %v", err)
+	}
+	if err := svc.Update(ctx, 1, "https://example.com", "", time.Time{}, url.DetailsPatch{}); !errors.Is(err, user.ErrUnauthorized) {
+		t.Errorf("Update: expected ErrUnauthorized, got: %v", err)
+	}
+	if err := svc.Delete(ctx, 1); !errors.Is(err, user.ErrUnauthorized) {
+		t.Errorf("Delete: expected ErrUnauthorized, got: %v", err)
+	}
+}
+
+// --- Dedupe tests ---
+
+func TestSave_Dedupe_ReturnsExistingAlias(t *testing.T) {
+	repo := &mockRepo{
+		getByHashFn: func(ctx context.Context, urlHash string) (url.Url, error) {
+			if urlHash != HashUrl("https://example.com") {
+				t.Errorf("unexpected hash: %s", urlHash)
+			}
+			return url.Url{Id: 1, Alias: "existing"}, nil
+		},
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			t.Error("repo save should not be called")
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, true)
+
+	u, err := svc.Save(userCtx(), "", "HTTPS://example.com/", "", time.Time{}, url.Details{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if u.Alias != "existing" {
+		t.Errorf("expected existing alias, got: %s", u.Alias)
+	}
+}
+
+func TestSave_Dedupe_InsertsWithHash(t *testing.T) {
+	var savedHash string
+	repo := &mockRepo{
+		getByHashFn: func(ctx context.Context, urlHash string) (url.Url, error) {
+			return url.Url{}, url.ErrNotFound
+		},
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			savedHash = urlHash
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	gen := &mockGenerator{aliases: []string{"fresh"}}
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, true)
+
+	u, err := svc.Save(userCtx(), "", "https://example.com", "", time.Time{}, url.Details{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if u.Alias != "fresh" {
+		t.Errorf("expected generated alias, got: %s", u.Alias)
+	}
+	if savedHash != HashUrl("https://example.com") {
+		t.Errorf("unexpected saved hash: %s", savedHash)
+	}
+}
+
+func TestSave_Dedupe_ConcurrentInsert(t *testing.T) {
+	lookups := 0
+	repo := &mockRepo{
+		getByHashFn: func(ctx context.Context, urlHash string) (url.Url, error) {
+			lookups++
+			if lookups == 1 {
+				return url.Url{}, url.ErrNotFound
+			}
+			return url.Url{Id: 2, Alias: "winner"}, nil
+		},
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			return url.Url{}, url.ErrDuplicateUrl
+		},
+	}
+	gen := &mockGenerator{aliases: []string{"loser"}}
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, true)
+
+	u, err := svc.Save(userCtx(), "", "https://example.com", "", time.Time{}, url.Details{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if u.Alias != "winner" {
+		t.Errorf("expected concurrent winner alias, got: %s", u.Alias)
+	}
+}
+
+func TestSave_Dedupe_SkippedForCustomAliasAndExpiry(t *testing.T) {
+	repo := &mockRepo{
+		getByHashFn: func(ctx context.Context, urlHash string) (url.Url, error) {
+			t.Error("hash lookup should not happen")
+			return url.Url{}, url.ErrNotFound
+		},
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			if urlHash != "" {
+				t.Errorf("expected no hash, got: %s", urlHash)
+			}
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	gen := &mockGenerator{aliases: []string{"generated"}}
+	svc := NewUrlService(repo, &mockDomainRepo{}, gen, newLogger(), testBaseUrl, testAliasPolicy, true)
+
+	if _, err := svc.Save(userCtx(), "", "https://example.com", "custom", time.Time{}, url.Details{}); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if _, err := svc.Save(userCtx(), "", "https://example.com", "", time.Now().Add(time.Hour), url.Details{}); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
 // --- List tests ---
 
 func TestList_Success(t *testing.T) {
 	expected := []url.Url{
-		{Id: 1, OriginalUrl: "https://example.com", Alias: "http://localhost/abc"},
-		{Id: 2, OriginalUrl: "https://google.com", Alias: "http://localhost/xyz"},
+		{Id: 1, OriginalUrl: "https://example.com", Alias: "abc"},
+		{Id: 2, OriginalUrl: "https://google.com", Alias: "xyz"},
 	}
 	repo := &mockRepo{
 		listFn: func(ctx context.Context) ([]url.Url, error) {
 			return expected, nil
 		},
 	}
-	svc := NewUrlService(repo, &mockGenerator{}, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	result, err := svc.List(context.Background())
+	result, err := svc.List(userCtx(), url.ListQuery{}, "")
 	if err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
-	if len(result) != len(expected) {
-		t.Errorf("expected %d urls, got %d", len(expected), len(result))
+	if len(result.Urls) != len(expected) {
+		t.Errorf("expected %d urls, got %d", len(expected), len(result.Urls))
+	}
+	if result.NextCursor != "" {
+		t.Errorf("expected no next cursor on the last page, got %q", result.NextCursor)
 	}
-	for i, u := range result {
+	for i, u := range result.Urls {
 		if u.Id != expected[i].Id || u.OriginalUrl != expected[i].OriginalUrl {
 			t.Errorf("url mismatch at index %d", i)
 		}
@@ -206,9 +675,9 @@ func TestList_RepoError(t *testing.T) {
 			return nil, repoErr
 		},
 	}
-	svc := NewUrlService(repo, &mockGenerator{}, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	_, err := svc.List(context.Background())
+	_, err := svc.List(userCtx(), url.ListQuery{}, "")
 	if !errors.Is(err, repoErr) {
 		t.Errorf("expected db error, got: %v", err)
 	}
@@ -217,7 +686,7 @@ func TestList_RepoError(t *testing.T) {
 // --- Get tests ---
 
 func TestGet_Success(t *testing.T) {
-	expected := url.Url{Id: 42, OriginalUrl: "https://example.com", Alias: "http://localhost/abc"}
+	expected := url.Url{Id: 42, OriginalUrl: "https://example.com", Alias: "abc", OwnerId: testUserId}
 	repo := &mockRepo{
 		getFn: func(ctx context.Context, id int) (url.Url, error) {
 			if id != 42 {
@@ -226,9 +695,9 @@ func TestGet_Success(t *testing.T) {
 			return expected, nil
 		},
 	}
-	svc := NewUrlService(repo, &mockGenerator{}, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	result, err := svc.Get(context.Background(), 42)
+	result, err := svc.Get(userCtx(), 42)
 	if err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
@@ -237,40 +706,187 @@ func TestGet_Success(t *testing.T) {
 	}
 }
 
+func TestGet_OtherOwner(t *testing.T) {
+	repo := &mockRepo{
+		getFn: func(ctx context.Context, id int) (url.Url, error) {
+			return url.Url{Id: id, OwnerId: testUserId + 1}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	_, err := svc.Get(userCtx(), 42)
+	if !errors.Is(err, url.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another owner's url, got: %v", err)
+	}
+}
+
+func TestGet_AdminSeesOtherOwners(t *testing.T) {
+	repo := &mockRepo{
+		getFn: func(ctx context.Context, id int) (url.Url, error) {
+			return url.Url{Id: id, OwnerId: testUserId + 1}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	if _, err := svc.Get(adminCtx(), 42); err != nil {
+		t.Errorf("expected admin to see another owner's url, got: %v", err)
+	}
+}
+
+func TestList_AdminIsUnscoped(t *testing.T) {
+	repo := &mockRepo{
+		ownerId: -1,
+		listFn: func(ctx context.Context) ([]url.Url, error) {
+			return nil, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	if _, err := svc.List(adminCtx(), url.ListQuery{}, ""); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if repo.ownerId != 0 {
+		t.Errorf("expected unscoped list for admin, got owner %d", repo.ownerId)
+	}
+}
+
+func TestSave_ReservedAlias(t *testing.T) {
+	repo := &mockRepo{
+		saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	if _, err := svc.Save(userCtx(), "", "https://example.com", "health", time.Time{}, url.Details{}); !errors.Is(err, url.ErrInvalidAlias) {
+		t.Errorf("expected ErrInvalidAlias for a non-admin, got: %v", err)
+	}
+	u, err := svc.Save(adminCtx(), "", "https://example.com", "health", time.Time{}, url.Details{})
+	if err != nil {
+		t.Fatalf("expected admin to claim a reserved alias, got: %v", err)
+	}
+	if u.Alias != "health" {
+		t.Errorf("expected alias health, got %s", u.Alias)
+	}
+}
+
 func TestGet_NotFound(t *testing.T) {
 	repo := &mockRepo{
 		getFn: func(ctx context.Context, id int) (url.Url, error) {
 			return url.Url{}, url.ErrNotFound
 		},
 	}
-	svc := NewUrlService(repo, &mockGenerator{}, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	_, err := svc.Get(context.Background(), 99)
+	_, err := svc.Get(userCtx(), 99)
 	if !errors.Is(err, url.ErrNotFound) {
 		t.Errorf("expected ErrNotFound, got: %v", err)
 	}
 }
 
+// --- GetByAlias tests ---
+
+func TestGetByAlias_Success(t *testing.T) {
+	expected := url.Url{Id: 7, OriginalUrl: "https://example.com", Alias: "abc123"}
+	repo := &mockRepo{
+		getByAliasFn: func(ctx context.Context, alias string) (url.Url, error) {
+			if alias != "abc123" {
+				t.Errorf("expected alias abc123, got %s", alias)
+			}
+			return expected, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	result, err := svc.GetByAlias(context.Background(), "localhost:8080", "abc123")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if result.Id != expected.Id || result.OriginalUrl != expected.OriginalUrl {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestGetByAlias_NormalizesHost(t *testing.T) {
+	repo := &mockRepo{
+		getByAliasFn: func(ctx context.Context, alias string) (url.Url, error) {
+			return url.Url{Alias: alias}, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	if _, err := svc.GetByAlias(context.Background(), "Go.Example.COM:443", "docs"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if repo.host != "go.example.com" {
+		t.Errorf("expected normalized host, got %q", repo.host)
+	}
+}
+
+func TestGetByAlias_NotFound(t *testing.T) {
+	repo := &mockRepo{
+		getByAliasFn: func(ctx context.Context, alias string) (url.Url, error) {
+			return url.Url{}, url.ErrNotFound
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	_, err := svc.GetByAlias(context.Background(), "localhost:8080", "missing")
+	if !errors.Is(err, url.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestGetByAlias_Expired(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mockRepo{
+		getByAliasFn: func(ctx context.Context, alias string) (url.Url, error) {
+			return url.Url{Id: 1, Alias: alias, ExpiresAt: now.Add(-time.Second)}, nil
+		},
+	}
+	svc := &urlService{repo: repo, log: newLogger(), policy: testAliasPolicy, now: func() time.Time { return now }}
+
+	_, err := svc.GetByAlias(context.Background(), "localhost:8080", "old")
+	if !errors.Is(err, url.ErrExpired) {
+		t.Errorf("expected ErrExpired, got: %v", err)
+	}
+}
+
+func TestGetByAlias_NotYetExpired(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mockRepo{
+		getByAliasFn: func(ctx context.Context, alias string) (url.Url, error) {
+			return url.Url{Id: 1, Alias: alias, ExpiresAt: now.Add(time.Second)}, nil
+		},
+	}
+	svc := &urlService{repo: repo, log: newLogger(), policy: testAliasPolicy, now: func() time.Time { return now }}
+
+	_, err := svc.GetByAlias(context.Background(), "localhost:8080", "fresh")
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
 // --- Update tests ---
 
 func TestUpdate_WithAlias_Success(t *testing.T) {
 	repo := &mockRepo{
-		updateFn: func(ctx context.Context, id int, newUrl, alias string) error {
+		updateFn: func(ctx context.Context, id int, newUrl, alias string, expiresAt time.Time) error {
 			if id != 1 {
 				t.Errorf("expected id 1, got %d", id)
 			}
 			if newUrl != "https://new.com" {
 				t.Errorf("unexpected url: %s", newUrl)
 			}
-			if alias != "http://localhost/new-alias" {
+			if alias != "new-alias" {
 				t.Errorf("unexpected alias: %s", alias)
 			}
 			return nil
 		},
 	}
-	svc := NewUrlService(repo, &mockGenerator{}, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	err := svc.Update(context.Background(), 1, "https://new.com", "new-alias")
+	err := svc.Update(userCtx(), 1, "https://new.com", "new-alias", time.Time{}, url.DetailsPatch{})
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -278,16 +894,16 @@ func TestUpdate_WithAlias_Success(t *testing.T) {
 
 func TestUpdate_WithoutAlias_Success(t *testing.T) {
 	repo := &mockRepo{
-		updateFn: func(ctx context.Context, id int, newUrl, alias string) error {
+		updateFn: func(ctx context.Context, id int, newUrl, alias string, expiresAt time.Time) error {
 			if alias != "" {
 				t.Errorf("expected empty alias, got: %s", alias)
 			}
 			return nil
 		},
 	}
-	svc := NewUrlService(repo, &mockGenerator{}, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	err := svc.Update(context.Background(), 1, "https://new.com", "")
+	err := svc.Update(userCtx(), 1, "https://new.com", "", time.Time{}, url.DetailsPatch{})
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -296,18 +912,48 @@ func TestUpdate_WithoutAlias_Success(t *testing.T) {
 func TestUpdate_RepoError(t *testing.T) {
 	repoErr := errors.New("db error")
 	repo := &mockRepo{
-		updateFn: func(ctx context.Context, id int, newUrl, alias string) error {
+		updateFn: func(ctx context.Context, id int, newUrl, alias string, expiresAt time.Time) error {
 			return repoErr
 		},
 	}
-	svc := NewUrlService(repo, &mockGenerator{}, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	err := svc.Update(context.Background(), 1, "https://new.com", "alias")
+	err := svc.Update(userCtx(), 1, "https://new.com", "alias", time.Time{}, url.DetailsPatch{})
 	if !errors.Is(err, repoErr) {
 		t.Errorf("expected db error, got: %v", err)
 	}
 }
 
+func TestUpdate_ExpiryInPast(t *testing.T) {
+	repo := &mockRepo{
+		updateFn: func(ctx context.Context, id int, newUrl, alias string, expiresAt time.Time) error {
+			t.Error("repo should not be called")
+			return nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	err := svc.Update(userCtx(), 1, "https://new.com", "", time.Now().Add(-time.Hour), url.DetailsPatch{})
+	if !errors.Is(err, url.ErrInvalidExpiry) {
+		t.Errorf("expected ErrInvalidExpiry, got: %v", err)
+	}
+}
+
+func TestUpdate_InvalidAlias(t *testing.T) {
+	repo := &mockRepo{
+		updateFn: func(ctx context.Context, id int, newUrl, alias string, expiresAt time.Time) error {
+			t.Error("repo should not be called")
+			return nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	err := svc.Update(userCtx(), 1, "https://new.com", "a b", time.Time{}, url.DetailsPatch{})
+	if !errors.Is(err, url.ErrInvalidAlias) {
+		t.Errorf("expected ErrInvalidAlias, got: %v", err)
+	}
+}
+
 // --- Delete tests ---
 
 func TestDelete_Success(t *testing.T) {
@@ -319,14 +965,224 @@ func TestDelete_Success(t *testing.T) {
 			return nil
 		},
 	}
-	svc := NewUrlService(repo, &mockGenerator{}, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	err := svc.Delete(context.Background(), 5)
+	err := svc.Delete(userCtx(), 5)
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
 }
 
+func TestDelete_ScopedToOwner(t *testing.T) {
+	repo := &mockRepo{
+		deleteFn: func(ctx context.Context, id int) error {
+			return url.ErrNotFound
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	err := svc.Delete(userCtx(), 5)
+	if !errors.Is(err, url.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+	if repo.ownerId != testUserId {
+		t.Errorf("expected delete scoped to owner %d, got %d", testUserId, repo.ownerId)
+	}
+}
+
+func TestDelete_AdminIsUnscoped(t *testing.T) {
+	repo := &mockRepo{
+		ownerId: -1,
+		deleteFn: func(ctx context.Context, id int) error {
+			return nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	if err := svc.Delete(adminCtx(), 5); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if repo.ownerId != 0 {
+		t.Errorf("expected unscoped delete for admin, got owner %d", repo.ownerId)
+	}
+}
+
+// --- Workspace tests ---
+
+func TestSave_InWorkspace(t *testing.T) {
+	domains := &mockDomainRepo{domains: []url.Domain{
+		{Id: 3, Host: "go.example.com", WorkspaceId: 7},
+		{Id: 4, Host: "links.example.com"},
+		{Id: 5, Host: "ops.example.com", WorkspaceId: 8},
+	}}
+
+	tests := []struct {
+		name       string
+		ws         workspace.Workspace
+		host       string
+		wantDomain int
+		wantErr    error
+	}{
+		{"workspace default domain", workspace.Workspace{Id: 7, Name: "docs", Domain: "go.example.com"}, "", 3, nil},
+		{"without domain", workspace.Workspace{Id: 9, Name: "misc"}, "", 0, nil},
+		{"explicit shared domain", workspace.Workspace{Id: 7, Name: "docs", Domain: "go.example.com"}, "Links.Example.com", 4, nil},
+		{"other workspace's domain", workspace.Workspace{Id: 7, Name: "docs"}, "ops.example.com", 0, url.ErrUnknownDomain},
+		{"unregistered domain", workspace.Workspace{Id: 7, Name: "docs"}, "nope.example.com", 0, url.ErrUnknownDomain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{
+				saveFn: func(ctx context.Context, urlToSave, alias, urlHash string, expiresAt time.Time) (url.Url, error) {
+					return url.Url{Alias: alias}, nil
+				},
+			}
+			svc := NewUrlService(repo, domains, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+			_, err := svc.Save(workspaceCtx(tt.ws), tt.host, "https://example.com", "docs", time.Time{}, url.Details{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got: %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if repo.saved.WorkspaceId != tt.ws.Id || repo.saved.OwnerId != testUserId {
+				t.Errorf("unexpected workspace/owner: %+v", repo.saved)
+			}
+			if repo.saved.DomainId != tt.wantDomain {
+				t.Errorf("expected domain %d, got %d", tt.wantDomain, repo.saved.DomainId)
+			}
+		})
+	}
+}
+
+func TestSave_PersonalLinkRejectsWorkspaceDomain(t *testing.T) {
+	domains := &mockDomainRepo{domains: []url.Domain{{Id: 3, Host: "go.example.com", WorkspaceId: 7}}}
+	svc := NewUrlService(&mockRepo{}, domains, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	_, err := svc.Save(userCtx(), "go.example.com", "https://example.com", "docs", time.Time{}, url.Details{})
+	if !errors.Is(err, url.ErrUnknownDomain) {
+		t.Errorf("expected ErrUnknownDomain, got: %v", err)
+	}
+}
+
+func TestList_InWorkspace(t *testing.T) {
+	repo := &mockRepo{
+		listFn: func(ctx context.Context) ([]url.Url, error) {
+			return nil, nil
+		},
+	}
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+	if _, err := svc.List(workspaceCtx(workspace.Workspace{Id: 7}), url.ListQuery{}, ""); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if repo.scope != (url.Scope{WorkspaceId: 7}) {
+		t.Errorf("expected workspace scope, got %+v", repo.scope)
+	}
+}
+
+func TestGet_WorkspaceScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		link    url.Url
+		wantErr error
+	}{
+		{
+			"teammate's link in workspace",
+			workspaceCtx(workspace.Workspace{Id: 7}),
+			url.Url{Id: 1, OwnerId: testUserId + 1, WorkspaceId: 7},
+			nil,
+		},
+		{
+			"other workspace's link",
+			workspaceCtx(workspace.Workspace{Id: 7}),
+			url.Url{Id: 1, OwnerId: testUserId, WorkspaceId: 8},
+			url.ErrNotFound,
+		},
+		{
+			"personal link from inside a workspace",
+			workspaceCtx(workspace.Workspace{Id: 7}),
+			url.Url{Id: 1, OwnerId: testUserId},
+			url.ErrNotFound,
+		},
+		{
+			"own workspace link outside the workspace",
+			userCtx(),
+			url.Url{Id: 1, OwnerId: testUserId, WorkspaceId: 7},
+			url.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{
+				getFn: func(ctx context.Context, id int) (url.Url, error) {
+					return tt.link, nil
+				},
+			}
+			svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+			_, err := svc.Get(tt.ctx, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWrites_WorkspaceScope(t *testing.T) {
+	ws := workspace.Workspace{Id: 7}
+	adminInWorkspace := workspace.WithWorkspace(adminCtx(), ws)
+
+	writes := map[string]func(svc UrlService, ctx context.Context) error{
+		"update": func(svc UrlService, ctx context.Context) error {
+			return svc.Update(ctx, 5, "https://example.com", "", time.Time{}, url.DetailsPatch{})
+		},
+		"delete": func(svc UrlService, ctx context.Context) error {
+			return svc.Delete(ctx, 5)
+		},
+		"update many": func(svc UrlService, ctx context.Context) error {
+			_, err := svc.UpdateMany(ctx, []url.Change{{Id: 5, NewUrl: "https://example.com"}})
+			return err
+		},
+		"delete many": func(svc UrlService, ctx context.Context) error {
+			_, err := svc.DeleteMany(ctx, []int{5})
+			return err
+		},
+	}
+
+	for name, write := range writes {
+		for _, tt := range []struct {
+			who   string
+			ctx   context.Context
+			scope url.Scope
+		}{
+			// members only change the workspace links they own
+			{"member", workspaceCtx(ws), url.Scope{WorkspaceId: 7, OwnerId: testUserId}},
+			{"admin", adminInWorkspace, url.Scope{WorkspaceId: 7}},
+		} {
+			t.Run(name+"/"+tt.who, func(t *testing.T) {
+				repo := &mockRepo{
+					updateFn:     func(ctx context.Context, id int, newUrl, alias string, expiresAt time.Time) error { return nil },
+					deleteFn:     func(ctx context.Context, id int) error { return nil },
+					updateManyFn: func(ctx context.Context, changes []url.Change) ([]error, error) { return []error{nil}, nil },
+					deleteManyFn: func(ctx context.Context, ids []int) ([]error, error) { return []error{nil}, nil },
+				}
+				svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
+
+				if err := write(svc, tt.ctx); err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				if repo.scope != tt.scope {
+					t.Errorf("expected scope %+v, got %+v", tt.scope, repo.scope)
+				}
+			})
+		}
+	}
+}
+
 func TestDelete_RepoError(t *testing.T) {
 	repoErr := errors.New("db error")
 	repo := &mockRepo{
@@ -334,18 +1190,44 @@ func TestDelete_RepoError(t *testing.T) {
 			return repoErr
 		},
 	}
-	svc := NewUrlService(repo, &mockGenerator{}, newLogger(), "http://localhost")
+	svc := NewUrlService(repo, &mockDomainRepo{}, &mockGenerator{}, newLogger(), testBaseUrl, testAliasPolicy, false)
 
-	err := svc.Delete(context.Background(), 5)
+	err := svc.Delete(userCtx(), 5)
 	if !errors.Is(err, repoErr) {
 		t.Errorf("expected db error, got: %v", err)
 	}
 }
 
+// --- ShortUrl tests ---
+
+func TestShortUrl(t *testing.T) {
+	baseUrl := func(domain string) string {
+		if domain == "" {
+			return "http://localhost:8080/"
+		}
+		return "https://" + domain
+	}
+	svc := NewUrlService(&mockRepo{}, &mockDomainRepo{}, &mockGenerator{}, newLogger(), baseUrl, testAliasPolicy, false)
+
+	tests := []struct {
+		link url.Url
+		want string
+	}{
+		{url.Url{Alias: "abc123"}, "http://localhost:8080/abc123"},
+		{url.Url{Alias: "abc123", DomainId: 3, Domain: "go.example.com"}, "https://go.example.com/abc123"},
+	}
+
+	for _, tt := range tests {
+		if got := svc.ShortUrl(tt.link); got != tt.want {
+			t.Errorf("ShortUrl(%+v) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
 // --- BuildShortUrl tests ---
 
 func TestBuildShortUrl(t *testing.T) {
-	svc := &urlService{baseUrl: "http://localhost"}
+	svc := &urlService{baseUrl: testBaseUrl}
 
 	tests := []struct {
 		baseUrl string
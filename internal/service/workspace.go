@@ -0,0 +1,100 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/workspace"
+	"awesomeProject/internal/repositiries"
+	"context"
+	"fmt"
+	"strings"
+)
+
+type WorkspaceService interface {
+	// Create adds a workspace with the caller as its first member. domain is
+	// optional; when set it is registered as the workspace's default domain.
+	Create(ctx context.Context, name, domain string) (workspace.Workspace, error)
+	// List returns the caller's workspaces, or every workspace for admins.
+	List(ctx context.Context) ([]workspace.Workspace, error)
+	AddMember(ctx context.Context, workspaceName, userName string) error
+	// Enter resolves the workspace the caller wants to act in. Workspaces the
+	// caller is not a member of are reported as missing; admins may enter any.
+	Enter(ctx context.Context, name string) (workspace.Workspace, error)
+}
+
+type workspaceService struct {
+	repo  repositiries.WorkspaceRepository
+	users repositiries.UserRepository
+}
+
+func NewWorkspaceService(repo repositiries.WorkspaceRepository, users repositiries.UserRepository) WorkspaceService {
+	return &workspaceService{repo: repo, users: users}
+}
+
+func (s *workspaceService) Create(ctx context.Context, name, domain string) (workspace.Workspace, error) {
+	ownerId, err := callerId(ctx)
+	if err != nil {
+		return workspace.Workspace{}, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return workspace.Workspace{}, fmt.Errorf("%w: name is required", workspace.ErrInvalid)
+	}
+	if domain != "" {
+		domain, err = normalizeDomain(domain)
+		if err != nil {
+			return workspace.Workspace{}, err
+		}
+	}
+
+	return s.repo.Create(ctx, name, domain, ownerId)
+}
+
+func (s *workspaceService) List(ctx context.Context) ([]workspace.Workspace, error) {
+	userId, err := callerId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin(ctx) {
+		userId = 0
+	}
+
+	return s.repo.ListForUser(ctx, userId)
+}
+
+func (s *workspaceService) AddMember(ctx context.Context, workspaceName, userName string) error {
+	w, err := s.repo.GetByName(ctx, workspaceName)
+	if err != nil {
+		return err
+	}
+	u, err := s.users.GetByName(ctx, userName)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.AddMember(ctx, w.Id, u.Id)
+}
+
+func (s *workspaceService) Enter(ctx context.Context, name string) (workspace.Workspace, error) {
+	userId, err := callerId(ctx)
+	if err != nil {
+		return workspace.Workspace{}, err
+	}
+
+	w, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return workspace.Workspace{}, err
+	}
+	if isAdmin(ctx) {
+		return w, nil
+	}
+
+	member, err := s.repo.IsMember(ctx, w.Id, userId)
+	if err != nil {
+		return workspace.Workspace{}, err
+	}
+	if !member {
+		return workspace.Workspace{}, workspace.ErrNotFound
+	}
+
+	return w, nil
+}
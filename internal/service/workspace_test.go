@@ -0,0 +1,159 @@
+package service
+
+import (
+	"awesomeProject/internal/domain/url"
+	"awesomeProject/internal/domain/user"
+	"awesomeProject/internal/domain/workspace"
+	"context"
+	"errors"
+	"testing"
+)
+
+type mockWorkspaceRepo struct {
+	workspaces []workspace.Workspace
+	members    map[int][]int // workspace id -> user ids
+	listedFor  int
+}
+
+func (m *mockWorkspaceRepo) Create(
+	ctx context.Context,
+	name, domain string,
+	creatorId int,
+) (workspace.Workspace, error) {
+	w := workspace.Workspace{Id: len(m.workspaces) + 1, Name: name, Domain: domain}
+	m.workspaces = append(m.workspaces, w)
+	m.AddMember(ctx, w.Id, creatorId)
+	return w, nil
+}
+
+func (m *mockWorkspaceRepo) GetByName(ctx context.Context, name string) (workspace.Workspace, error) {
+	for _, w := range m.workspaces {
+		if w.Name == name {
+			return w, nil
+		}
+	}
+	return workspace.Workspace{}, workspace.ErrNotFound
+}
+
+func (m *mockWorkspaceRepo) ListForUser(ctx context.Context, userId int) ([]workspace.Workspace, error) {
+	m.listedFor = userId
+	return m.workspaces, nil
+}
+
+func (m *mockWorkspaceRepo) AddMember(ctx context.Context, workspaceId, userId int) error {
+	if m.members == nil {
+		m.members = make(map[int][]int)
+	}
+	m.members[workspaceId] = append(m.members[workspaceId], userId)
+	return nil
+}
+
+func (m *mockWorkspaceRepo) IsMember(ctx context.Context, workspaceId, userId int) (bool, error) {
+	for _, id := range m.members[workspaceId] {
+		if id == userId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestWorkspace_CreateAddsCreator(t *testing.T) {
+	repo := &mockWorkspaceRepo{}
+	svc := NewWorkspaceService(repo, &mockUserRepo{})
+
+	w, err := svc.Create(userCtx(), " docs ", "Go.Example.com")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if w.Name != "docs" || w.Domain != "go.example.com" {
+		t.Errorf("unexpected workspace: %+v", w)
+	}
+	if member, _ := repo.IsMember(context.Background(), w.Id, testUserId); !member {
+		t.Error("expected creator to be a member")
+	}
+}
+
+func TestWorkspace_CreateInvalid(t *testing.T) {
+	svc := NewWorkspaceService(&mockWorkspaceRepo{}, &mockUserRepo{})
+
+	tests := []struct {
+		name, domain string
+		wantErr      error
+	}{
+		{"", "", workspace.ErrInvalid},
+		{"docs", "localhost", url.ErrInvalidDomain},
+		{"docs", "https://go.example.com/", url.ErrInvalidDomain},
+	}
+	for _, tc := range tests {
+		if _, err := svc.Create(userCtx(), tc.name, tc.domain); !errors.Is(err, tc.wantErr) {
+			t.Errorf("Create(%q, %q): expected %v, got: %v", tc.name, tc.domain, tc.wantErr, err)
+		}
+	}
+}
+
+func TestWorkspace_Enter(t *testing.T) {
+	repo := &mockWorkspaceRepo{
+		workspaces: []workspace.Workspace{{Id: 1, Name: "docs"}},
+		members:    map[int][]int{1: {testUserId}},
+	}
+	svc := NewWorkspaceService(repo, &mockUserRepo{})
+
+	tests := []struct {
+		name    string
+		caller  user.User
+		ws      string
+		wantErr error
+	}{
+		{"member", user.User{Id: testUserId}, "docs", nil},
+		{"non-member", user.User{Id: testUserId + 1}, "docs", workspace.ErrNotFound},
+		{"admin non-member", user.User{Id: testUserId + 1, Roles: []string{user.RoleAdmin}}, "docs", nil},
+		{"missing workspace", user.User{Id: testUserId}, "ops", workspace.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := user.WithUser(context.Background(), tt.caller)
+			w, err := svc.Enter(ctx, tt.ws)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got: %v", tt.wantErr, err)
+			}
+			if err == nil && w.Name != tt.ws {
+				t.Errorf("expected workspace %s, got %+v", tt.ws, w)
+			}
+		})
+	}
+}
+
+func TestWorkspace_AddMember(t *testing.T) {
+	repo := &mockWorkspaceRepo{workspaces: []workspace.Workspace{{Id: 1, Name: "docs"}}}
+	users := &mockUserRepo{users: []user.User{{Id: 5, Name: "bob"}}}
+	svc := NewWorkspaceService(repo, users)
+
+	if err := svc.AddMember(adminCtx(), "docs", "bob"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if member, _ := repo.IsMember(context.Background(), 1, 5); !member {
+		t.Error("expected bob to be a member")
+	}
+	if err := svc.AddMember(adminCtx(), "docs", "carol"); !errors.Is(err, user.ErrNotFound) {
+		t.Errorf("expected user.ErrNotFound, got: %v", err)
+	}
+}
+
+func TestWorkspace_ListForAdminIsUnscoped(t *testing.T) {
+	repo := &mockWorkspaceRepo{listedFor: -1}
+	svc := NewWorkspaceService(repo, &mockUserRepo{})
+
+	if _, err := svc.List(userCtx()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if repo.listedFor != testUserId {
+		t.Errorf("expected list for user %d, got %d", testUserId, repo.listedFor)
+	}
+	if _, err := svc.List(adminCtx()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if repo.listedFor != 0 {
+		t.Errorf("expected unscoped list for admin, got %d", repo.listedFor)
+	}
+}
@@ -0,0 +1,88 @@
+package transfer
+
+import (
+	"awesomeProject/internal/domain/url"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Row is a data row of an imported CSV file.
+type Row struct {
+	Line  int
+	Draft url.Draft
+	// Err is set when the row could not be turned into a draft.
+	Err error
+}
+
+// ReadCSV parses a CSV file of links. The header row names the columns:
+// original_url is required; alias, expires_at (RFC 3339) and tags (comma
+// separated) are optional; other columns are ignored, so exports can be
+// imported back. Malformed CSV fails the whole file; unusable values only
+// fail their row. A file with more than maxRows data rows fails with
+// url.ErrBatchTooLarge as soon as the row past the limit is read.
+func ReadCSV(r io.Reader, maxRows int) ([]Row, error) {
+	reader := csv.NewReader(r)
+	// rows may leave out trailing optional columns
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty file")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// spreadsheets may start the file with a byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["original_url"]; !ok {
+		return nil, errors.New("missing original_url column")
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("%w: at most %d rows are allowed", url.ErrBatchTooLarge, maxRows)
+		}
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(csvUnsafe(record[i]))
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := Row{
+			Line: line,
+			Draft: url.Draft{
+				OriginalUrl: field("original_url"),
+				Alias:       field("alias"),
+			},
+		}
+		if v := field("expires_at"); v != "" {
+			row.Draft.ExpiresAt, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				row.Err = errors.New("invalid expires_at: " + err.Error())
+			}
+		}
+		if v := field("tags"); v != "" {
+			row.Draft.Tags = strings.Split(v, ",")
+		}
+		rows = append(rows, row)
+	}
+}
@@ -0,0 +1,124 @@
+package transfer
+
+import (
+	"awesomeProject/internal/domain/url"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCSV(t *testing.T) {
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		file string
+		// want holds the rows read; a row with wantErr set only reports
+		// whether it failed
+		want    []Row
+		wantErr []bool
+	}{
+		{
+			name: "all columns",
+			file: "original_url,alias,expires_at,tags\n" +
+				"https://example.com,docs,2030-01-02T03:04:05Z,\"a,b\"\n",
+			want: []Row{{Line: 2}},
+		},
+		{
+			name: "byte order mark and header case",
+			file: "\ufeffOriginal_URL, Alias\nhttps://example.com,docs\n",
+			want: []Row{{Line: 2}},
+		},
+		{
+			name: "short rows and unknown columns",
+			file: "id,original_url,alias,expires_at\n" +
+				"1,https://example.com\n" +
+				"2,https://example.org,org\n" +
+				"3\n",
+			want: []Row{{Line: 2}, {Line: 3}, {Line: 4}},
+		},
+		{
+			name: "bad expires_at fails its row only",
+			file: "original_url,expires_at\n" +
+				"https://example.com,tomorrow\n" +
+				"https://example.org,\n",
+			want:    []Row{{Line: 2}, {Line: 3}},
+			wantErr: []bool{true, false},
+		},
+		{
+			name: "quoted line breaks keep line numbers",
+			file: "original_url,title\n" +
+				"https://example.com,\"two\nlines\"\n" +
+				"https://example.org,one\n",
+			want: []Row{{Line: 2}, {Line: 4}},
+		},
+		{
+			name: "header only",
+			file: "original_url\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadCSV(strings.NewReader(tt.file), 10)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("expected %d rows, got %d: %+v", len(tt.want), len(rows), rows)
+			}
+			for i, row := range rows {
+				if row.Line != tt.want[i].Line {
+					t.Errorf("row %d: expected line %d, got %d", i, tt.want[i].Line, row.Line)
+				}
+				wantErr := tt.wantErr != nil && tt.wantErr[i]
+				if (row.Err != nil) != wantErr {
+					t.Errorf("row %d: expected error %v, got: %v", i, wantErr, row.Err)
+				}
+			}
+		})
+	}
+
+	rows, _ := ReadCSV(strings.NewReader(tests[0].file), 10)
+	d := rows[0].Draft
+	if d.OriginalUrl != "https://example.com" || d.Alias != "docs" || !d.ExpiresAt.Equal(expiry) ||
+		!slices.Equal(d.Tags, []string{"a", "b"}) {
+		t.Errorf("unexpected draft: %+v", d)
+	}
+	rows, _ = ReadCSV(strings.NewReader(tests[1].file), 10)
+	if d := rows[0].Draft; d.OriginalUrl != "https://example.com" || d.Alias != "docs" {
+		t.Errorf("unexpected draft behind a byte order mark: %+v", d)
+	}
+	rows, _ = ReadCSV(strings.NewReader(tests[2].file), 10)
+	if d := rows[2].Draft; d.OriginalUrl != "" || d.Alias != "" {
+		t.Errorf("expected blank fields for a short row, got: %+v", d)
+	}
+}
+
+func TestReadCSV_Invalid(t *testing.T) {
+	for name, file := range map[string]string{
+		"empty file":         "",
+		"missing url column": "alias,tags\ndocs,a\n",
+		"bare quote":         "original_url\nhttps://exa\"mple.com\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadCSV(strings.NewReader(file), 10); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestReadCSV_MaxRows(t *testing.T) {
+	file := "original_url\nhttps://example.com/a\nhttps://example.com/b\n"
+
+	rows, err := ReadCSV(strings.NewReader(file), 2)
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d, err: %v", len(rows), err)
+	}
+	if _, err := ReadCSV(strings.NewReader(file), 1); !errors.Is(err, url.ErrBatchTooLarge) {
+		t.Errorf("expected ErrBatchTooLarge, got: %v", err)
+	}
+}
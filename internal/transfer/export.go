@@ -0,0 +1,173 @@
+package transfer
+
+import (
+	"awesomeProject/internal/domain/url"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats.
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+var ErrUnknownFormat = errors.New("unknown format")
+
+var csvColumns = []string{
+	"id", "short_url", "original_url", "alias", "domain", "created_at", "expires_at", "clicks",
+	"tags", "title", "description",
+}
+
+// Record is one exported link in the json and ndjson formats.
+type Record struct {
+	Id          int            `json:"id"`
+	OriginalUrl string         `json:"original_url"`
+	Alias       string         `json:"alias"`
+	Domain      string         `json:"domain,omitempty"`
+	ShortUrl    string         `json:"short_url"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Clicks      int            `json:"clicks"`
+	ExpiresAt   *time.Time     `json:"expires_at,omitempty"`
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+}
+
+func NewRecord(u url.Url, shortUrl string) Record {
+	r := Record{
+		Id:          u.Id,
+		OriginalUrl: u.OriginalUrl,
+		Alias:       u.Alias,
+		Domain:      u.Domain,
+		ShortUrl:    shortUrl,
+		CreatedAt:   u.CreatedAt,
+		Clicks:      u.Clicks,
+		Title:       u.Title,
+		Description: u.Description,
+		Tags:        u.Tags,
+		Metadata:    u.Metadata,
+	}
+	if !u.ExpiresAt.IsZero() {
+		r.ExpiresAt = &u.ExpiresAt
+	}
+	return r
+}
+
+// Encoder writes exported links in one format: Begin once, Encode per link
+// and End after the last one. Flush writes out buffered rows.
+type Encoder interface {
+	Begin() error
+	Encode(r Record) error
+	Flush() error
+	End() error
+}
+
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}, nil
+	case FormatJSON, FormatNDJSON:
+		return &jsonEncoder{w: w, enc: json.NewEncoder(w), array: format == FormatJSON}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// csvFormulaPrefixes are the first characters that make spreadsheets
+// evaluate a cell as a formula.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvSafe keeps spreadsheets from evaluating a user supplied value as a
+// formula by prefixing it with a quote, which they show as text.
+func csvSafe(v string) string {
+	if v != "" && strings.IndexByte(csvFormulaPrefixes, v[0]) >= 0 {
+		return "'" + v
+	}
+	return v
+}
+
+// csvUnsafe reverts csvSafe so that exported files import unchanged.
+func csvUnsafe(v string) string {
+	if len(v) > 1 && v[0] == '\'' && strings.IndexByte(csvFormulaPrefixes, v[1]) >= 0 {
+		return v[1:]
+	}
+	return v
+}
+
+// csvEncoder writes csvColumns; tags are joined by commas and metadata is
+// left out. Text cells are made safe to open in a spreadsheet.
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) Begin() error {
+	return e.w.Write(csvColumns)
+}
+
+func (e *csvEncoder) Encode(r Record) error {
+	var expiresAt string
+	if r.ExpiresAt != nil {
+		expiresAt = r.ExpiresAt.Format(time.RFC3339)
+	}
+	return e.w.Write([]string{
+		strconv.Itoa(r.Id), csvSafe(r.ShortUrl), csvSafe(r.OriginalUrl), csvSafe(r.Alias), csvSafe(r.Domain),
+		r.CreatedAt.Format(time.RFC3339), expiresAt, strconv.Itoa(r.Clicks),
+		csvSafe(strings.Join(r.Tags, ",")), csvSafe(r.Title), csvSafe(r.Description),
+	})
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) End() error {
+	return e.Flush()
+}
+
+// jsonEncoder writes one JSON object per line, wrapped in an array unless
+// it is writing NDJSON.
+type jsonEncoder struct {
+	w     io.Writer
+	enc   *json.Encoder
+	array bool
+	rows  int
+}
+
+func (e *jsonEncoder) Begin() error {
+	if !e.array {
+		return nil
+	}
+	_, err := io.WriteString(e.w, "[\n")
+	return err
+}
+
+func (e *jsonEncoder) Encode(r Record) error {
+	if e.array && e.rows > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.rows++
+	return e.enc.Encode(r)
+}
+
+func (e *jsonEncoder) Flush() error {
+	return nil
+}
+
+func (e *jsonEncoder) End() error {
+	if !e.array {
+		return nil
+	}
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
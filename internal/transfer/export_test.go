@@ -0,0 +1,74 @@
+package transfer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCsvEncoder_NeutralizesFormulas(t *testing.T) {
+	var out strings.Builder
+	enc, err := NewEncoder(FormatCSV, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Record{
+		Id:          1,
+		OriginalUrl: "https://example.com",
+		Alias:       "docs",
+		CreatedAt:   time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		Title:       `=HYPERLINK("https://evil.test","click")`,
+		Description: "+cmd|' /C calc'!A0",
+		Tags:        []string{"@sum", "ok"},
+	}
+	if err := enc.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(r); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.End(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cell := range []string{`"'=HYPERLINK(""https://evil.test"",""click"")"`, `'+cmd|' /C calc'!A0`, `"'@sum,ok"`} {
+		if !strings.Contains(out.String(), cell) {
+			t.Errorf("expected cell %s in:\n%s", cell, out.String())
+		}
+	}
+
+	// exports import back unchanged
+	rows, err := ReadCSV(strings.NewReader(strings.Replace(out.String(), "title", "alias", 1)), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[0].Draft.Alias != r.Title || strings.Join(rows[0].Draft.Tags, ",") != "@sum,ok" {
+		t.Errorf("expected the quote prefix removed on import, got: %+v", rows[0].Draft)
+	}
+}
+
+func TestCsvSafe(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"docs", "docs"},
+		{"=1+1", "'=1+1"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@a", "'@a"},
+		{"\tx", "'\tx"},
+		{"\rx", "'\rx"},
+		{"a=b", "a=b"},
+		{"'quoted", "'quoted"},
+	}
+
+	for _, tc := range tests {
+		if got := csvSafe(tc.in); got != tc.want {
+			t.Errorf("csvSafe(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+		if got := csvUnsafe(csvSafe(tc.in)); got != tc.in {
+			t.Errorf("csvUnsafe(csvSafe(%q)) = %q", tc.in, got)
+		}
+	}
+}
@@ -1 +1 @@
-drop table if exists url;
\ No newline at end of file
+drop table if exists url;
@@ -0,0 +1,2 @@
+drop table if exists url_archive;
+drop index if exists url_expires_at_idx;
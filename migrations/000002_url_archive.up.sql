@@ -0,0 +1,11 @@
+create index if not exists url_expires_at_idx on url (expires_at) where expires_at is not null;
+
+create table if not exists url_archive (
+    id integer primary key,
+    original_url text not null,
+    alias text not null,
+    created_at timestamptz not null,
+    expires_at timestamptz,
+    clicks bigint not null default 0,
+    archived_at timestamptz not null default now()
+);
@@ -0,0 +1,11 @@
+create table if not exists click_events (
+    id bigserial primary key,
+    url_id integer not null references url (id) on delete cascade,
+    clicked_at timestamptz not null default now(),
+    referrer text not null default '',
+    user_agent_family text not null default '',
+    ip_prefix text not null default '',
+    request_id text not null default ''
+);
+
+create index if not exists click_events_url_id_clicked_at_idx on click_events (url_id, clicked_at);
@@ -0,0 +1,3 @@
+drop index if exists url_url_hash_key;
+
+alter table url drop column if exists url_hash;
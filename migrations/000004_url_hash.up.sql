@@ -0,0 +1,3 @@
+alter table url add column if not exists url_hash text;
+
+create unique index if not exists url_url_hash_key on url (url_hash);
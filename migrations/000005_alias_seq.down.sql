@@ -0,0 +1 @@
+drop sequence if exists url_alias_seq;
@@ -0,0 +1 @@
+create sequence if not exists url_alias_seq as bigint start with 1;
@@ -0,0 +1,4 @@
+create table if not exists alias_counter (
+    name text primary key,
+    next_id bigint not null
+);
@@ -0,0 +1,11 @@
+drop index if exists url_owner_id_url_hash_key;
+create unique index if not exists url_url_hash_key on url (url_hash);
+
+alter table url_archive drop column if exists owner_id;
+
+drop index if exists url_owner_id_idx;
+
+alter table url drop column if exists owner_id;
+
+drop table if exists api_keys;
+drop table if exists users;
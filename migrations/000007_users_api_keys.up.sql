@@ -0,0 +1,24 @@
+create table if not exists users (
+    id serial primary key,
+    name text unique not null,
+    created_at timestamptz not null default now()
+);
+
+create table if not exists api_keys (
+    id serial primary key,
+    user_id integer not null references users (id) on delete cascade,
+    key_hash text unique not null,
+    prefix text not null,
+    created_at timestamptz not null default now(),
+    revoked_at timestamptz
+);
+
+alter table url add column if not exists owner_id integer references users (id) on delete set null;
+
+create index if not exists url_owner_id_idx on url (owner_id);
+
+alter table url_archive add column if not exists owner_id integer;
+
+-- dedupe is per owner from now on
+drop index if exists url_url_hash_key;
+create unique index if not exists url_owner_id_url_hash_key on url (owner_id, url_hash);
@@ -0,0 +1,2 @@
+alter table users add column if not exists role text not null default 'editor'
+    check (role in ('admin', 'editor', 'viewer'));
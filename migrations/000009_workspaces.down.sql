@@ -0,0 +1,16 @@
+alter table url_archive drop column if exists workspace_id;
+
+drop index if exists url_owner_id_url_hash_key;
+create unique index if not exists url_owner_id_url_hash_key on url (owner_id, url_hash);
+
+drop index if exists url_namespace_alias_key;
+alter table url add constraint url_alias_key unique (alias);
+
+alter table url drop column if exists namespace;
+
+drop index if exists url_workspace_id_idx;
+
+alter table url drop column if exists workspace_id;
+
+drop table if exists workspace_members;
+drop table if exists workspaces;
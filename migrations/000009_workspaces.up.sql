@@ -0,0 +1,31 @@
+create table if not exists workspaces (
+    id serial primary key,
+    name text unique not null,
+    domain text unique,
+    created_at timestamptz not null default now()
+);
+
+create table if not exists workspace_members (
+    workspace_id integer not null references workspaces (id) on delete cascade,
+    user_id integer not null references users (id) on delete cascade,
+    created_at timestamptz not null default now(),
+    primary key (workspace_id, user_id)
+);
+
+create index if not exists workspace_members_user_id_idx on workspace_members (user_id);
+
+alter table url add column if not exists workspace_id integer references workspaces (id) on delete cascade;
+
+create index if not exists url_workspace_id_idx on url (workspace_id);
+
+-- aliases are unique per namespace: the id of a workspace bound to a domain,
+-- or 0 for everything served on the default host
+alter table url add column if not exists namespace integer not null default 0;
+
+alter table url drop constraint if exists url_alias_key;
+create unique index if not exists url_namespace_alias_key on url (namespace, alias);
+
+drop index if exists url_owner_id_url_hash_key;
+create unique index if not exists url_owner_id_url_hash_key on url (owner_id, coalesce(workspace_id, 0), url_hash);
+
+alter table url_archive add column if not exists workspace_id integer;
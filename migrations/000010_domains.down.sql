@@ -0,0 +1,23 @@
+alter table url_archive drop column if exists domain_id;
+
+alter table workspaces add column if not exists domain text unique;
+
+update workspaces w set domain = (
+    select d.host from domains d where d.workspace_id = w.id order by d.id limit 1
+);
+
+alter table url add column if not exists namespace integer not null default 0;
+
+update url set namespace = d.workspace_id
+from domains d
+where d.id = url.domain_id and d.workspace_id is not null;
+
+drop index if exists url_owner_id_url_hash_key;
+create unique index if not exists url_owner_id_url_hash_key on url (owner_id, coalesce(workspace_id, 0), url_hash);
+
+drop index if exists url_domain_id_alias_key;
+create unique index if not exists url_namespace_alias_key on url (namespace, alias);
+
+alter table url drop column if exists domain_id;
+
+drop table if exists domains;
@@ -0,0 +1,32 @@
+create table if not exists domains (
+    id serial primary key,
+    host text unique not null,
+    -- null for shared domains any caller may use
+    workspace_id integer references workspaces (id) on delete cascade,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists domains_workspace_id_idx on domains (workspace_id);
+
+insert into domains (host, workspace_id)
+select domain, id from workspaces where domain is not null
+on conflict (host) do nothing;
+
+alter table url add column if not exists domain_id integer references domains (id) on delete cascade;
+
+update url set domain_id = d.id
+from domains d
+where url.namespace <> 0 and d.workspace_id = url.namespace;
+
+-- aliases are unique per domain, links without one live on the default host
+drop index if exists url_namespace_alias_key;
+alter table url drop column if exists namespace;
+create unique index if not exists url_domain_id_alias_key on url (coalesce(domain_id, 0), alias);
+
+drop index if exists url_owner_id_url_hash_key;
+create unique index if not exists url_owner_id_url_hash_key
+    on url (owner_id, coalesce(workspace_id, 0), coalesce(domain_id, 0), url_hash);
+
+alter table workspaces drop column if exists domain;
+
+alter table url_archive add column if not exists domain_id integer;
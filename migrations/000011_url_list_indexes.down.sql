@@ -0,0 +1,3 @@
+drop index if exists url_alias_prefix_idx;
+drop index if exists url_clicks_id_idx;
+drop index if exists url_created_at_id_idx;
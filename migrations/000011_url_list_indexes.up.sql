@@ -0,0 +1,3 @@
+create index if not exists url_created_at_id_idx on url (created_at, id);
+create index if not exists url_clicks_id_idx on url (clicks, id);
+create index if not exists url_alias_prefix_idx on url (alias text_pattern_ops);
@@ -0,0 +1,3 @@
+drop index if exists url_search_idx;
+
+alter table url drop column if exists search;
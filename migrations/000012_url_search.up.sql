@@ -0,0 +1,8 @@
+-- punctuation is turned into spaces so that the words inside urls and
+-- hyphenated aliases become separate lexemes
+alter table url add column if not exists search tsvector generated always as (
+    setweight(to_tsvector('simple', regexp_replace(alias, '[^[:alnum:]]+', ' ', 'g')), 'A') ||
+    setweight(to_tsvector('simple', regexp_replace(original_url, '[^[:alnum:]]+', ' ', 'g')), 'B')
+) stored;
+
+create index if not exists url_search_idx on url using gin (search);
@@ -0,0 +1,21 @@
+drop index if exists url_search_idx;
+alter table url drop column if exists search;
+alter table url add column search tsvector generated always as (
+    setweight(to_tsvector('simple', regexp_replace(alias, '[^[:alnum:]]+', ' ', 'g')), 'A') ||
+    setweight(to_tsvector('simple', regexp_replace(original_url, '[^[:alnum:]]+', ' ', 'g')), 'B')
+) stored;
+create index if not exists url_search_idx on url using gin (search);
+
+drop function if exists url_tags_text(text[]);
+
+alter table url_archive drop column if exists metadata;
+alter table url_archive drop column if exists description;
+alter table url_archive drop column if exists title;
+alter table url_archive drop column if exists tags;
+
+drop index if exists url_tags_idx;
+
+alter table url drop column if exists metadata;
+alter table url drop column if exists description;
+alter table url drop column if exists title;
+alter table url drop column if exists tags;
@@ -0,0 +1,29 @@
+alter table url add column if not exists tags text[] not null default '{}';
+alter table url add column if not exists title text;
+alter table url add column if not exists description text;
+alter table url add column if not exists metadata jsonb not null default '{}';
+
+create index if not exists url_tags_idx on url using gin (tags);
+
+alter table url_archive add column if not exists tags text[] not null default '{}';
+alter table url_archive add column if not exists title text;
+alter table url_archive add column if not exists description text;
+alter table url_archive add column if not exists metadata jsonb not null default '{}';
+
+-- array_to_string is only stable, generated columns need an immutable
+-- expression; joining text elements does not depend on any setting
+create or replace function url_tags_text(tags text[]) returns text
+    language sql immutable parallel safe
+    as $$ select array_to_string(tags, ' ') $$;
+
+-- rebuild the search vector to cover title and tags as well
+drop index if exists url_search_idx;
+alter table url drop column if exists search;
+alter table url add column search tsvector generated always as (
+    setweight(to_tsvector('simple', regexp_replace(alias, '[^[:alnum:]]+', ' ', 'g')), 'A') ||
+    setweight(to_tsvector('simple', regexp_replace(url_tags_text(tags), '[^[:alnum:]]+', ' ', 'g')), 'A') ||
+    setweight(to_tsvector('simple', regexp_replace(coalesce(title, ''), '[^[:alnum:]]+', ' ', 'g')), 'B') ||
+    setweight(to_tsvector('simple', regexp_replace(original_url, '[^[:alnum:]]+', ' ', 'g')), 'C')
+) stored;
+
+create index if not exists url_search_idx on url using gin (search);
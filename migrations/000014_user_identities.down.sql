@@ -0,0 +1,4 @@
+drop index if exists users_identity_key;
+
+alter table users drop column if exists subject;
+alter table users drop column if exists issuer;
@@ -0,0 +1,6 @@
+-- JWT callers are looked up by token issuer and subject, never by name, so
+-- a token cannot act as an API key user that happens to share its subject
+alter table users add column if not exists issuer text;
+alter table users add column if not exists subject text;
+
+create unique index if not exists users_identity_key on users (issuer, subject);
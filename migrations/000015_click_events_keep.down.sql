@@ -0,0 +1,4 @@
+delete from click_events e where not exists (select 1 from url where url.id = e.url_id);
+
+alter table click_events add constraint click_events_url_id_fkey
+    foreign key (url_id) references url (id) on delete cascade;
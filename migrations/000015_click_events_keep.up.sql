@@ -0,0 +1,4 @@
+-- click events outlive their link: a link deleted before its clicks are
+-- flushed no longer fails the whole batch, and archived links keep their
+-- analytics. Deleting or purging a link removes its events explicitly.
+alter table click_events drop constraint if exists click_events_url_id_fkey;
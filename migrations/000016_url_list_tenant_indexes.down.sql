@@ -0,0 +1,7 @@
+create index if not exists url_owner_id_idx on url (owner_id);
+create index if not exists url_workspace_id_idx on url (workspace_id);
+
+drop index if exists url_workspace_id_clicks_id_idx;
+drop index if exists url_owner_id_clicks_id_idx;
+drop index if exists url_workspace_id_created_at_id_idx;
+drop index if exists url_owner_id_created_at_id_idx;
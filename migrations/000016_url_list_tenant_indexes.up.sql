@@ -0,0 +1,10 @@
+-- every listing outside the admin scope filters on owner_id or workspace_id,
+-- so keyset pages have to start from the tenant, not the global order.
+create index if not exists url_owner_id_created_at_id_idx on url (owner_id, created_at, id);
+create index if not exists url_workspace_id_created_at_id_idx on url (workspace_id, created_at, id);
+create index if not exists url_owner_id_clicks_id_idx on url (owner_id, clicks, id);
+create index if not exists url_workspace_id_clicks_id_idx on url (workspace_id, clicks, id);
+
+-- the single-column tenant indexes are prefixes of the ones above.
+drop index if exists url_owner_id_idx;
+drop index if exists url_workspace_id_idx;
@@ -0,0 +1,2 @@
+-- the cleared hashes are recomputed by the service only, nothing to restore
+select 1;
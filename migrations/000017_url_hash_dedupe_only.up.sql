@@ -0,0 +1,6 @@
+-- only links without an expiry or details are deduplicated: drop the hashes
+-- of links that gained either after they were created
+update url set url_hash = null
+where url_hash is not null
+  and (expires_at is not null or title is not null or description is not null
+       or tags <> '{}' or metadata <> '{}');
@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// FS holds the schema migrations, applied with pkg/migrate.
+//
+//go:embed *.sql
+var FS embed.FS
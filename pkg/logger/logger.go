@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
 	"os"
 )
@@ -12,20 +13,26 @@ const (
 )
 
 func NewLogger(env string) *slog.Logger {
+	return NewLoggerTo(env, os.Stdout)
+}
+
+// NewLoggerTo is NewLogger writing to w, e.g. stderr for commands whose
+// output goes to stdout.
+func NewLoggerTo(env string, w io.Writer) *slog.Logger {
 	var log *slog.Logger
 
 	switch env {
 	case envLocal:
 		log = slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+			slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}),
 		)
 	case envDev:
 		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+			slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}),
 		)
 	case envProd:
 		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+			slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo}),
 		)
 	}
 
@@ -0,0 +1,281 @@
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrDirty = errors.New("database is dirty, fix the failed migration and reset its version by hand")
+
+// fileName matches golang-migrate style files, e.g. 000001_init.up.sql.
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is the pair of scripts sharing a version.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load reads the migrations in the root of fsys, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has scripts named %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(script)
+		} else {
+			m.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	return migrations, nil
+}
+
+// lockKey is the advisory lock serializing migrations across replicas.
+const lockKey int64 = 0x75726c5f6d6967 // "url_mig"
+
+// Migrator applies migrations, recording the version reached in the
+// schema_migrations table the way golang-migrate does, so databases migrated
+// with either tool stay interchangeable. Each migration runs in its own
+// transaction together with the version update, and a run holds an advisory
+// lock so replicas starting together apply every migration once.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+	log        *slog.Logger
+}
+
+func New(pool *pgxpool.Pool, fsys fs.FS, logger *slog.Logger) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations, log: logger}, nil
+}
+
+// Version returns the version of the last applied migration, 0 if none was.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	var version int64
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		var err error
+		version, err = currentVersion(ctx, conn)
+		return err
+	})
+	return version, err
+}
+
+// Up applies the pending migrations and returns how many it applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range upSteps(m.migrations, current) {
+			if err := m.apply(ctx, conn, s); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts up to steps applied migrations, newest first, and returns how
+// many it reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		plan, err := downSteps(m.migrations, current, steps)
+		if err != nil {
+			return err
+		}
+		for _, s := range plan {
+			if err := m.apply(ctx, conn, s); err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// step is one script to run and the version it leaves the database at.
+type step struct {
+	migration Migration
+	script    string
+	version   int64
+}
+
+// upSteps plans the migrations newer than current, oldest first.
+func upSteps(migrations []Migration, current int64) []step {
+	var plan []step
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+		plan = append(plan, step{migration: migration, script: migration.Up, version: migration.Version})
+	}
+	return plan
+}
+
+// downSteps plans reverting up to steps migrations applied by current, newest
+// first, each one leaving the database at the version of the migration before
+// it. A migration without a down script fails the whole plan before anything
+// is reverted.
+func downSteps(migrations []Migration, current int64, steps int) ([]step, error) {
+	var plan []step
+	for i := len(migrations) - 1; i >= 0 && len(plan) < steps; i-- {
+		migration := migrations[i]
+		if migration.Version > current {
+			continue
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+		}
+		var previous int64
+		if i > 0 {
+			previous = migrations[i-1].Version
+		}
+		plan = append(plan, step{migration: migration, script: migration.Down, version: previous})
+	}
+	return plan, nil
+}
+
+// locked runs fn on a connection holding the migration lock, waiting for
+// other replicas to release it first.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "select pg_advisory_lock($1)", lockKey); err != nil {
+		return err
+	}
+	defer func() {
+		// the lock is session level: a connection that failed to unlock
+		// must not return to the pool still holding it
+		if _, err := conn.Exec(context.WithoutCancel(ctx), "select pg_advisory_unlock($1)", lockKey); err != nil {
+			conn.Conn().Close(context.WithoutCancel(ctx))
+		}
+	}()
+
+	return fn(conn)
+}
+
+func currentVersion(ctx context.Context, conn *pgxpool.Conn) (int64, error) {
+	if _, err := conn.Exec(ctx, `
+		create table if not exists schema_migrations (
+			version bigint not null primary key,
+			dirty boolean not null
+		)`); err != nil {
+		return 0, err
+	}
+
+	var version int64
+	var dirty bool
+	err := conn.QueryRow(ctx, "select version, dirty from schema_migrations limit 1").Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return version, fmt.Errorf("%w: version %d", ErrDirty, version)
+	}
+
+	return version, nil
+}
+
+// apply runs the script of s and records its version as the current one.
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, s step) error {
+	migration, version := s.migration, s.version
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, s.script); err != nil {
+		return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.Exec(ctx, "delete from schema_migrations"); err != nil {
+		return err
+	}
+	if version > 0 {
+		if _, err := tx.Exec(ctx, "insert into schema_migrations (version, dirty) values ($1, false)", version); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	m.log.Info(
+		"applied migration",
+		slog.Int64("migration", migration.Version),
+		slog.String("name", migration.Name),
+		slog.Int64("version", version),
+	)
+	return nil
+}
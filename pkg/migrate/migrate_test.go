@@ -0,0 +1,165 @@
+package migrate
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"awesomeProject/migrations"
+)
+
+func file(script string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(script)}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000010_later.up.sql":   file("up 10"),
+		"000010_later.down.sql": file("down 10"),
+		"000002_second.up.sql":  file("up 2"),
+		"000001_init.down.sql":  file("down 1"),
+		"000001_init.up.sql":    file("up 1"),
+		"README.md":             file("not a migration"),
+		"000003_nested/x.sql":   file("ignored"),
+	}
+
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	want := []Migration{
+		{Version: 1, Name: "init", Up: "up 1", Down: "down 1"},
+		{Version: 2, Name: "second", Up: "up 2"},
+		{Version: 10, Name: "later", Up: "up 10", Down: "down 10"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %+v, got: %+v", want, got)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing up script": {
+			"000001_init.up.sql":     file("up 1"),
+			"000002_second.down.sql": file("down 2"),
+		},
+		"mismatched names": {
+			"000001_init.up.sql":    file("up 1"),
+			"000001_other.down.sql": file("down 1"),
+		},
+		"version zero": {
+			"000000_init.up.sql": file("up 0"),
+		},
+	}
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(fsys); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	loaded, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, m := range loaded {
+		if m.Version != int64(i+1) {
+			t.Errorf("expected version %d, got %d_%s", i+1, m.Version, m.Name)
+		}
+		if m.Down == "" {
+			t.Errorf("expected a down script for %d_%s", m.Version, m.Name)
+		}
+	}
+}
+
+func TestUpSteps(t *testing.T) {
+	loaded := []Migration{
+		{Version: 1, Name: "a", Up: "up 1"},
+		{Version: 2, Name: "b", Up: "up 2"},
+		{Version: 5, Name: "c", Up: "up 5"},
+	}
+
+	tests := []struct {
+		name    string
+		current int64
+		want    []int64
+	}{
+		{"fresh database", 0, []int64{1, 2, 5}},
+		{"partly migrated", 2, []int64{5}},
+		{"up to date", 5, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			for _, s := range upSteps(loaded, tt.current) {
+				if s.script != s.migration.Up || s.version != s.migration.Version {
+					t.Errorf("expected step %d to run up and reach its version, got: %+v", s.migration.Version, s)
+				}
+				got = append(got, s.version)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected versions %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDownSteps(t *testing.T) {
+	loaded := []Migration{
+		{Version: 1, Name: "a", Up: "up 1", Down: "down 1"},
+		{Version: 2, Name: "b", Up: "up 2", Down: "down 2"},
+		{Version: 5, Name: "c", Up: "up 5", Down: "down 5"},
+	}
+
+	tests := []struct {
+		name    string
+		current int64
+		steps   int
+		// want holds the migration reverted and the version it leaves, in
+		// order
+		want [][2]int64
+	}{
+		{"one step", 5, 1, [][2]int64{{5, 2}}},
+		{"gap in versions", 5, 2, [][2]int64{{5, 2}, {2, 1}}},
+		{"down to nothing", 5, 10, [][2]int64{{5, 2}, {2, 1}, {1, 0}}},
+		{"skips migrations not applied", 2, 1, [][2]int64{{2, 1}}},
+		{"nothing applied", 0, 1, nil},
+		{"no steps", 5, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := downSteps(loaded, tt.current, tt.steps)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			var got [][2]int64
+			for _, s := range plan {
+				if s.script != s.migration.Down {
+					t.Errorf("expected the down script of %d, got: %q", s.migration.Version, s.script)
+				}
+				got = append(got, [2]int64{s.migration.Version, s.version})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDownSteps_MissingDownScript(t *testing.T) {
+	loaded := []Migration{
+		{Version: 1, Name: "a", Up: "up 1"},
+		{Version: 2, Name: "b", Up: "up 2", Down: "down 2"},
+	}
+
+	if _, err := downSteps(loaded, 2, 2); err == nil {
+		t.Error("expected an error before reverting anything")
+	}
+	plan, err := downSteps(loaded, 2, 1)
+	if err != nil || len(plan) != 1 {
+		t.Errorf("expected the last migration reverted alone, got %+v, err: %v", plan, err)
+	}
+}